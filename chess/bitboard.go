@@ -0,0 +1,341 @@
+package chess
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+type bitboard uint64
+
+const (
+	fileA        bitboard = 0x0101010101010101
+	fileH        bitboard = fileA << 7
+	rank1        bitboard = 0xff
+	rank8        bitboard = rank1 << 56
+	lightSquares bitboard = 0x55aa55aa55aa55aa
+)
+
+type board struct {
+	squares  [64]Piece
+	pieces   [12]bitboard
+	occupied [2]bitboard
+	hash     uint64
+}
+
+type magic struct {
+	mask    bitboard
+	number  uint64
+	shift   uint
+	attacks []bitboard
+}
+
+var orthogonals = []Square{{0, 1}, {1, 0}, {0, -1}, {-1, 0}}
+var diagonals = []Square{{1, 1}, {1, -1}, {-1, -1}, {-1, 1}}
+var knightJumps = []Square{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+
+var (
+	knightAttacks [64]bitboard
+	kingAttacks   [64]bitboard
+	pawnAttacks   [2][64]bitboard
+	rookMagics    [64]magic
+	bishopMagics  [64]magic
+	between       [64][64]bitboard
+)
+
+func init() {
+	for i := 0; i < 64; i++ {
+		from := squareAt(i)
+		knightAttacks[i] = jumps(from, knightJumps)
+		kingAttacks[i] = jumps(from, orthogonals) | jumps(from, diagonals)
+		pawnAttacks[White.index()][i] = jumps(from, []Square{{-1, 1}, {1, 1}})
+		pawnAttacks[Black.index()][i] = jumps(from, []Square{{-1, -1}, {1, -1}})
+		rookMagics[i] = newMagic(from, orthogonals, rookMagicNumbers[i])
+		bishopMagics[i] = newMagic(from, diagonals, bishopMagicNumbers[i])
+	}
+	for i := 0; i < 64; i++ {
+		for j := 0; j < 64; j++ {
+			if rookAttacks(i, 0)&squareAt(j).bit() != 0 {
+				between[i][j] = rookAttacks(i, squareAt(j).bit()) & rookAttacks(j, squareAt(i).bit())
+			} else if bishopAttacks(i, 0)&squareAt(j).bit() != 0 {
+				between[i][j] = bishopAttacks(i, squareAt(j).bit()) & bishopAttacks(j, squareAt(i).bit())
+			}
+		}
+	}
+}
+
+func jumps(from Square, offsets []Square) (b bitboard) {
+	for _, d := range offsets {
+		if to := (Square{from.file + d.file, from.row + d.row}); withinBounds(to) {
+			b |= to.bit()
+		}
+	}
+	return
+}
+
+func slide(from Square, directions []Square, occupied bitboard) (b bitboard) {
+	for _, d := range directions {
+		for to := (Square{from.file + d.file, from.row + d.row}); withinBounds(to); to.file, to.row = to.file+d.file, to.row+d.row {
+			b |= to.bit()
+			if occupied&to.bit() != 0 {
+				break
+			}
+		}
+	}
+	return
+}
+
+func newMagic(from Square, directions []Square, number uint64) magic {
+	edges := (rank1|rank8)&^(rank1<<(8*from.row)) | (fileA|fileH)&^(fileA<<from.file)
+	m := magic{mask: slide(from, directions, 0) &^ edges, number: number}
+	m.shift = uint(64 - m.mask.count())
+	m.attacks = make([]bitboard, 1<<m.mask.count())
+	for subset := bitboard(0); ; {
+		m.attacks[m.index(subset)] = slide(from, directions, subset)
+		subset = (subset - m.mask) & m.mask
+		if subset == 0 {
+			break
+		}
+	}
+	return m
+}
+
+func (m *magic) index(occupied bitboard) uint64 {
+	return (uint64(occupied&m.mask) * m.number) >> m.shift
+}
+
+func rookAttacks(i int, occupied bitboard) bitboard {
+	m := &rookMagics[i]
+	return m.attacks[m.index(occupied)]
+}
+
+func bishopAttacks(i int, occupied bitboard) bitboard {
+	m := &bishopMagics[i]
+	return m.attacks[m.index(occupied)]
+}
+
+func (b bitboard) count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+func (b bitboard) first() int {
+	return bits.TrailingZeros64(uint64(b))
+}
+
+func (b *bitboard) pop() int {
+	i := b.first()
+	*b &= *b - 1
+	return i
+}
+
+func (sq Square) index() int {
+	return sq.row*8 + sq.file
+}
+
+func (sq Square) bit() bitboard {
+	return 1 << sq.index()
+}
+
+func squareAt(i int) Square {
+	return Square{i % 8, i / 8}
+}
+
+func (p Color) index() int {
+	if p == White {
+		return 0
+	}
+	return 1
+}
+
+func (board *board) at(sq Square) Piece {
+	return board.squares[sq.index()]
+}
+
+func (board *board) set(sq Square, p Piece) {
+	i := sq.index()
+	if old := board.squares[i]; old != empty {
+		board.pieces[old.index()] &^= sq.bit()
+		board.occupied[old.Color().index()] &^= sq.bit()
+		board.hash ^= zobristPieces[old.index()][i]
+	}
+	board.squares[i] = p
+	if p != empty {
+		board.pieces[p.index()] |= sq.bit()
+		board.occupied[p.Color().index()] |= sq.bit()
+		board.hash ^= zobristPieces[p.index()][i]
+	}
+}
+
+func (board *board) clear() {
+	for i := range board.squares {
+		board.squares[i] = empty
+	}
+	board.pieces = [12]bitboard{}
+	board.occupied = [2]bitboard{}
+	board.hash = 0
+}
+
+func (board *board) bitboardOf(p Piece) bitboard {
+	return board.pieces[p.index()]
+}
+
+func (board *board) all() bitboard {
+	return board.occupied[0] | board.occupied[1]
+}
+
+func (board *board) attacks(from Square, occupied bitboard) bitboard {
+	i := from.index()
+	switch piece := board.at(from); piece.Kind() {
+	case King:
+		return kingAttacks[i]
+	case Queen:
+		return rookAttacks(i, occupied) | bishopAttacks(i, occupied)
+	case Rook:
+		return rookAttacks(i, occupied)
+	case Bishop:
+		return bishopAttacks(i, occupied)
+	case Knight:
+		return knightAttacks[i]
+	case Pawn:
+		return pawnAttacks[piece.Color().index()][i]
+	}
+	return 0
+}
+
+func (board *board) pawnPushes(from Square) bitboard {
+	dir, startRow := 1, _2
+	if board.at(from) == bpawn {
+		dir, startRow = -1, _7
+	}
+	to := Square{from.file, from.row + dir}
+	if board.at(to) != empty {
+		return 0
+	}
+	pushes := to.bit()
+	if from.row == startRow && board.at(Square{from.file, from.row + 2*dir}) == empty {
+		pushes |= Square{from.file, from.row + 2*dir}.bit()
+	}
+	return pushes
+}
+
+func (board *board) attacked(i int, attacker Color, occupied, captured bitboard) bool {
+	pieces := board.pieces[:6]
+	if attacker == Black {
+		pieces = board.pieces[6:]
+	}
+	queens := pieces[wqueen.index()]
+	return knightAttacks[i]&pieces[wknight.index()]&^captured != 0 ||
+		kingAttacks[i]&pieces[wking.index()]&^captured != 0 ||
+		pawnAttacks[(!attacker).index()][i]&pieces[wpawn.index()]&^captured != 0 ||
+		rookAttacks(i, occupied)&(pieces[wrook.index()]|queens)&^captured != 0 ||
+		bishopAttacks(i, occupied)&(pieces[wbishop.index()]|queens)&^captured != 0
+}
+
+func (board *board) attackers(i int, attacker Color, occupied, captured bitboard) bitboard {
+	pieces := board.pieces[:6]
+	if attacker == Black {
+		pieces = board.pieces[6:]
+	}
+	queens := pieces[wqueen.index()]
+	return (knightAttacks[i]&pieces[wknight.index()] |
+		kingAttacks[i]&pieces[wking.index()] |
+		pawnAttacks[(!attacker).index()][i]&pieces[wpawn.index()] |
+		rookAttacks(i, occupied)&(pieces[wrook.index()]|queens) |
+		bishopAttacks(i, occupied)&(pieces[wbishop.index()]|queens)) &^ captured
+}
+
+func (board *board) pinned(player Color) (pinned bitboard) {
+	king := board.bitboardOf(colored(wking, player))
+	if king == 0 {
+		return 0
+	}
+	k := king.first()
+	queens := board.bitboardOf(colored(wqueen, !player))
+	snipers := rookAttacks(k, 0)&(board.bitboardOf(colored(wrook, !player))|queens) |
+		bishopAttacks(k, 0)&(board.bitboardOf(colored(wbishop, !player))|queens)
+	for snipers != 0 {
+		blockers := between[k][snipers.pop()] & board.all()
+		if blockers.count() == 1 && blockers&board.occupied[player.index()] != 0 {
+			pinned |= blockers
+		}
+	}
+	return
+}
+
+func (board *board) squareAttackedByPlayer(sq Square, attacker Color) bool {
+	return board.attacked(sq.index(), attacker, board.all(), 0)
+}
+
+func (board *board) kingIsCheckedAfter(from, to Square) (bool, error) {
+	return board.kingIsCheckedAfterMove(newMove(from, to, empty, 0))
+}
+
+func (board *board) kingIsCheckedAfterEnPassant(from, to Square) (bool, error) {
+	return board.kingIsCheckedAfterMove(newMove(from, to, empty, enPassantFlag))
+}
+
+func (board *board) kingIsCheckedAfterMove(m Move) (bool, error) {
+	from, to := m.From(), m.To()
+	piece := board.at(from)
+	if piece == empty {
+		return false, fmt.Errorf("Square %v is empty.", from)
+	}
+	player := piece.Color()
+	king := board.bitboardOf(colored(wking, player))
+	if piece == colored(wking, player) {
+		king = to.bit()
+	} else if king == 0 {
+		return false, fmt.Errorf("%v's king not found.", player)
+	}
+	captured := to.bit()
+	if m.is(enPassantFlag) {
+		captured = Square{to.file, from.row}.bit()
+	}
+	occupied := board.all()&^from.bit()&^captured | to.bit()
+	return board.attacked(king.first(), !player, occupied, captured), nil
+}
+
+func (board *board) findKingOf(player Color) (Square, error) {
+	king := board.bitboardOf(colored(wking, player))
+	if king == 0 {
+		return Square{0, 0}, fmt.Errorf("%v's king not found.", player)
+	}
+	return squareAt(king.first()), nil
+}
+
+var rookMagicNumbers = [64]uint64{
+	0x1080004008801020, 0x0840092002c03000, 0x1900200010400900, 0x0880100008000480,
+	0x4200100420080200, 0x8100020100080400, 0x0200040110886200, 0x0200008040220411,
+	0x0404800084400220, 0x0000401000402000, 0x0086001081220440, 0x0408800800100280,
+	0x000a001201040820, 0x8848800200840080, 0x4001000100040200, 0x0442000102105084,
+	0x9080010020804100, 0x0040404000201009, 0x0000808010002009, 0x2200090021d00100,
+	0x0008008008040080, 0x0004004002010040, 0x0011040008015042, 0x00000a0001768104,
+	0x0000800080204009, 0x2010004140002001, 0x9800200280100080, 0x1000100080080080,
+	0x0442000a00049020, 0x2100040080020080, 0x0800120400900148, 0x0010040a00128541,
+	0x2800804000800030, 0x1010002000400041, 0x4000200011004100, 0x0610008410800800,
+	0x0400802402800800, 0xc100020080800400, 0x0002000802000401, 0x0182085882000401,
+	0x0220204000808000, 0x2860100040024022, 0x0001002004110040, 0x99101042000a0020,
+	0x0004080004008080, 0x0010040002008080, 0x2012004881020004, 0x8300842444820011,
+	0x0088403882010200, 0x0820400080210100, 0x0110910040a00300, 0x0801100280080480,
+	0x0242009008200600, 0x1002000489500200, 0x0040800200010080, 0x0091800041000080,
+	0x0000209300488001, 0x04c1002414824001, 0x020020000b001041, 0x7000100004200901,
+	0x8002002004100802, 0x30010002084c0007, 0x0888221800813004, 0x4000002840840112,
+}
+
+var bishopMagicNumbers = [64]uint64{
+	0xa010041108003100, 0x006082020a002900, 0x6810010619200000, 0x08281a0520000408,
+	0x0001104001000400, 0x0018901008048400, 0x00040a0210245280, 0x000200210808a402,
+	0x9140048410821200, 0x0800091010820041, 0x20504804832202c0, 0x0100091401081000,
+	0x8021011140000012, 0x0810020804450400, 0x208b0542109008a2, 0x0080084a08040204,
+	0x0040e2a80811244c, 0x2505022008008108, 0x0430220100420040, 0x010a040420220040,
+	0x1105000290400000, 0x0093001200822120, 0x4000a62048043004, 0x280120048a015004,
+	0x006090002a020814, 0x44042000240800d0, 0x01102800040a4400, 0x1004080080220040,
+	0x0001001011004024, 0x0010044000805040, 0x0914041200820100, 0x0004821012821480,
+	0x0024040500c05021, 0x0088611002080200, 0x0116080a00040020, 0x4000020080080080,
+	0x2450450140840040, 0x0000880201484100, 0x0222020404020092, 0x8081110600002e00,
+	0x2842101105000801, 0x1100809008001025, 0x00020202221c0400, 0x0422014022009020,
+	0x0210046102100c00, 0xc004008082029102, 0x00aa461801101200, 0x0404080080201108,
+	0x020542108c205002, 0x0410544804100100, 0x0040910841100000, 0x0400200042021100,
+	0x00004204850400c0, 0x0200100410a42102, 0x1040020801210102, 0x0805040410420000,
+	0x2884804130100200, 0x800c262201242000, 0x1058000194108800, 0x0014221054420204,
+	0x0104000012a02200, 0x0200881003300100, 0x0140400202840100, 0x0402020801010201,
+}
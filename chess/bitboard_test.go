@@ -0,0 +1,89 @@
+package chess
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSlidingAttacks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 64; i++ {
+		for n := 0; n < 100; n++ {
+			occupied := bitboard(rng.Uint64() & rng.Uint64())
+			if got, want := rookAttacks(i, occupied), slide(squareAt(i), orthogonals, occupied); got != want {
+				t.Fatalf("Rook attacks from %v with occupancy %x should be %x but are %x.", squareAt(i), occupied, want, got)
+			}
+			if got, want := bishopAttacks(i, occupied), slide(squareAt(i), diagonals, occupied); got != want {
+				t.Fatalf("Bishop attacks from %v with occupancy %x should be %x but are %x.", squareAt(i), occupied, want,
+					got)
+			}
+		}
+	}
+}
+
+func TestLeaperAttacks(t *testing.T) {
+	tests := []struct {
+		name    string
+		attacks bitboard
+		want    []Square
+	}{
+		{"knight on a1", knightAttacks[Square{_a, _1}.index()], []Square{{_b, _3}, {_c, _2}}},
+		{"knight on e4", knightAttacks[Square{_e, _4}.index()],
+			[]Square{{_d, _2}, {_f, _2}, {_c, _3}, {_g, _3}, {_c, _5}, {_g, _5}, {_d, _6}, {_f, _6}}},
+		{"king on h8", kingAttacks[Square{_h, _8}.index()], []Square{{_g, _7}, {_h, _7}, {_g, _8}}},
+		{"white pawn on a2", pawnAttacks[White.index()][Square{_a, _2}.index()], []Square{{_b, _3}}},
+		{"black pawn on e7", pawnAttacks[Black.index()][Square{_e, _7}.index()], []Square{{_d, _6}, {_f, _6}}},
+	}
+	for _, test := range tests {
+		var want bitboard
+		for _, sq := range test.want {
+			want |= sq.bit()
+		}
+		if test.attacks != want {
+			t.Errorf("Attacks of %s should be %x but are %x.", test.name, want, test.attacks)
+		}
+	}
+}
+
+func TestBoardSet(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wknight)
+	board.set(Square{_e, _4}, bqueen)
+	if board.at(Square{_e, _4}) != bqueen {
+		t.Errorf("Square %v should hold %v but holds %v.", Square{_e, _4}, bqueen, board.at(Square{_e, _4}))
+	}
+	if board.bitboardOf(wknight) != 0 || board.bitboardOf(bqueen) != (Square{_e, _4}).bit() {
+		t.Errorf("Piece bitboards are not updated when a piece is replaced: %x", board.pieces)
+	}
+	if board.occupied[White.index()] != 0 || board.occupied[Black.index()] != (Square{_e, _4}).bit() {
+		t.Errorf("Occupancy is not updated when a piece is replaced: %x", board.occupied)
+	}
+	board.set(Square{_e, _4}, empty)
+	if board.all() != 0 || board.bitboardOf(bqueen) != 0 {
+		t.Errorf("Board should be empty after clearing the only piece but is %x.", board.all())
+	}
+}
+
+func TestPinned(t *testing.T) {
+	pos, err := ParseFEN("4k3/8/8/1b6/8/3N4/4P3/r2BKN1q w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Square{_d, _1}.bit() | Square{_f, _1}.bit()
+	if got := pos.board.pinned(White); got != want {
+		t.Errorf("Pinned pieces should be %x but are %x.", want, got)
+	}
+}
+
+func TestCountValidMoves(t *testing.T) {
+	for _, test := range perftPositions {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := pos.countValidMoves(), len(pos.validMoves()); got != want {
+			t.Errorf("Counted moves of %s should be %d but are %d.", test.name, want, got)
+		}
+	}
+}
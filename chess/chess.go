@@ -0,0 +1,677 @@
+package chess
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	_a int = iota
+	_b
+	_c
+	_d
+	_e
+	_f
+	_g
+	_h
+)
+const fileUnicodeOffset = 0x0061
+
+const (
+	_1 int = iota
+	_2
+	_3
+	_4
+	_5
+	_6
+	_7
+	_8
+)
+const rowUnicodeOffset = 0x0031
+
+type Color bool
+
+const (
+	White Color = true
+	Black Color = false
+)
+
+type castling uint8
+
+const (
+	whiteKingside castling = 1 << iota
+	whiteQueenside
+	blackKingside
+	blackQueenside
+)
+
+type Status uint8
+
+const (
+	Ongoing Status = iota
+	WhiteWins
+	BlackWins
+	Draw
+)
+
+type Reason uint8
+
+const (
+	NoReason Reason = iota
+	Checkmate
+	Stalemate
+	FiftyMoveRule
+	SeventyFiveMoveRule
+	ThreefoldRepetition
+	FivefoldRepetition
+	InsufficientMaterial
+)
+
+type Position struct {
+	board     board
+	turn      Color
+	castling  castling
+	enPassant *Square
+	setup     string
+	history   []move
+	future    []move
+	captured  []Piece
+	status    Status
+	reason    Reason
+	tags      map[string]string
+	result    string
+
+	halfmoveClock  int
+	fullmoveNumber int
+	repetitions    []uint64
+	undos          []undo
+}
+
+type move struct {
+	from      Square
+	to        Square
+	piece     Piece
+	captured  Piece
+	promotion Piece
+	enPassant bool
+	san       string
+}
+
+type irreversible struct {
+	castling      castling
+	enPassant     *Square
+	halfmoveClock int
+}
+
+type Square struct {
+	file int
+	row  int
+}
+
+func (position *Position) startingPos() {
+	var board *board = &(position.board)
+	board.clear()
+	for file, piece := range []Piece{wrook, wknight, wbishop, wqueen, wking, wbishop, wknight, wrook} {
+		board.set(Square{file, _1}, piece)
+		board.set(Square{file, _2}, wpawn)
+		board.set(Square{file, _7}, bpawn)
+		board.set(Square{file, _8}, colored(piece, Black))
+	}
+
+	position.turn = White
+	position.castling = whiteKingside | whiteQueenside | blackKingside | blackQueenside
+	position.enPassant = nil
+	position.setup = ""
+	position.history = nil
+	position.future = nil
+	position.captured = nil
+	position.status = Ongoing
+	position.reason = NoReason
+	position.tags = nil
+	position.result = ""
+	position.halfmoveClock = 0
+	position.fullmoveNumber = 1
+	position.repetitions = []uint64{position.Hash()}
+	position.undos = nil
+}
+
+func (position *Position) move(from, to Square, promotion Piece) error {
+	m, err := position.check(from, to, promotion)
+	if err != nil {
+		return err
+	}
+	m.san = position.sanWithoutSuffix(m)
+	position.apply(m)
+	position.updateStatus()
+	position.history[len(position.history)-1].san += position.sanSuffix()
+	position.future = nil
+	return nil
+}
+
+func (position *Position) check(from, to Square, promotion Piece) (move, error) {
+	if position.status != Ongoing {
+		return move{}, gameOverError(position.status, position.reason)
+	}
+	for _, sq := range []Square{from, to} {
+		if !withinBounds(sq) {
+			return move{}, &MoveError{Err: ErrOutOfBounds, From: from, To: to, Cause: sq}
+		}
+	}
+	if from == to {
+		return move{}, &MoveError{Err: ErrSameSquare, From: from, To: to}
+	}
+	var board *board = &(position.board)
+	piece := board.at(from)
+	if piece == empty {
+		return move{}, &MoveError{Err: ErrEmptySquare, From: from, To: to}
+	}
+	owner := piece.Color()
+	if position.turn != owner {
+		return move{}, &MoveError{Err: ErrWrongTurn, Piece: piece, From: from, To: to}
+	}
+	isCastling := piece.Kind() == King && abs(to.file-from.file) == 2
+	isEnPassant := position.validateEnPassant(from, to)
+	if isCastling {
+		if !position.validateCastling(from, to) {
+			return move{}, position.castlingError(from, to)
+		}
+	} else if !isEnPassant && !board.validateMove(from, to) {
+		return move{}, board.moveError(from, to)
+	}
+	var isCheckedAfter bool
+	var err error
+	if isEnPassant {
+		isCheckedAfter, err = board.kingIsCheckedAfterEnPassant(from, to)
+	} else {
+		isCheckedAfter, err = board.kingIsCheckedAfter(from, to)
+	}
+	if err != nil {
+		return move{}, err
+	}
+	if isCheckedAfter {
+		return move{}, board.checkError(from, to, isEnPassant)
+	}
+	if isPromotion(piece, to) {
+		if promotion == empty {
+			return move{}, &MoveError{Err: ErrMissingPromotion, Piece: piece, From: from, To: to}
+		}
+		promotion = colored(promotion, owner)
+		if !slices.Contains(promotionPieces(owner), promotion) {
+			return move{}, &MoveError{Err: ErrInvalidPromotion, Piece: piece, From: from, To: to, Promotion: promotion}
+		}
+	} else if promotion != empty {
+		return move{}, &MoveError{Err: ErrNotPromotion, Piece: piece, From: from, To: to, Promotion: promotion}
+	}
+
+	m := move{from: from, to: to, piece: piece, captured: board.at(to), promotion: promotion,
+		enPassant: isEnPassant}
+	if isEnPassant {
+		m.captured = board.at(Square{to.file, from.row})
+	}
+	return m, nil
+}
+
+func NewPosition() *Position {
+	position := &Position{}
+	position.startingPos()
+	return position
+}
+
+func (position *Position) Clone() *Position {
+	clone := *position
+	clone.history = slices.Clone(position.history)
+	clone.future = slices.Clone(position.future)
+	clone.captured = slices.Clone(position.captured)
+	clone.repetitions = slices.Clone(position.repetitions)
+	clone.undos = slices.Clone(position.undos)
+	clone.tags = maps.Clone(position.tags)
+	return &clone
+}
+
+func (position *Position) Turn() Color {
+	return position.turn
+}
+
+func (position *Position) FullmoveNumber() int {
+	return position.fullmoveNumber
+}
+
+func (position *Position) Status() Status {
+	return position.status
+}
+
+func (position *Position) Reason() Reason {
+	return position.reason
+}
+
+func (position *Position) At(sq Square) Piece {
+	if !withinBounds(sq) {
+		return empty
+	}
+	return position.board.at(sq)
+}
+
+func (position *Position) Captured() []Piece {
+	return slices.Clone(position.captured)
+}
+
+func (position *Position) LegalMoves() []Move {
+	if position.status != Ongoing {
+		return nil
+	}
+	var list moveList
+	position.generateMoves(&list)
+	return slices.Clone(list.moves[:list.size])
+}
+
+func (position *Position) ParseMove(s string) (Move, error) {
+	from, to, promotion, err := parseMove(s)
+	if err != nil {
+		from, to, promotion, err = parseUCIMove(s)
+	}
+	if err != nil {
+		m, sanErr := position.parseSAN(s)
+		if sanErr != nil {
+			return 0, sanErr
+		}
+		return m.packed(), nil
+	}
+	m := newMove(from, to, promotion, 0)
+	for _, legal := range position.LegalMoves() {
+		if legal.From() == from && legal.To() == to && legal.Promotion() == promotion.Kind() {
+			return legal, nil
+		}
+	}
+	return m, nil
+}
+
+func (position *Position) Apply(m Move) error {
+	if m.Promotion() > Pawn {
+		return &MoveError{Err: ErrInvalidPromotion, Piece: position.board.at(m.From()), From: m.From(), To: m.To()}
+	}
+	return position.move(m.From(), m.To(), m.promotion(position.turn))
+}
+
+func (position *Position) Format(set PieceSet) string {
+	return position.board.format(set)
+}
+
+func (position *Position) apply(m move) {
+	position.makeMove(m.packed())
+	if m.captured != empty {
+		position.captured = append(position.captured, m.captured)
+	}
+	position.history = append(position.history, m)
+	position.result = ""
+}
+
+func (position *Position) Undo() error {
+	if len(position.history) == 0 {
+		return fmt.Errorf("No move to undo.")
+	}
+	m := position.unapply()
+	position.status, position.reason = Ongoing, NoReason
+	position.future = append(position.future, m)
+	return nil
+}
+
+func (position *Position) unapply() move {
+	m := position.history[len(position.history)-1]
+	position.unmakeMove()
+	if m.captured != empty {
+		position.captured = position.captured[:len(position.captured)-1]
+	}
+	position.history = position.history[:len(position.history)-1]
+	position.result = ""
+	return m
+}
+
+func (position *Position) Redo() error {
+	if len(position.future) == 0 {
+		return fmt.Errorf("No move to redo.")
+	}
+	m := position.future[len(position.future)-1]
+	future := position.future[:len(position.future)-1]
+	if err := position.move(m.from, m.to, m.promotion); err != nil {
+		return err
+	}
+	position.future = future
+	return nil
+}
+
+func (position *Position) updateNoMovesStatus() {
+	if !position.InCheck() {
+		position.status, position.reason = Draw, Stalemate
+	} else if position.turn == White {
+		position.status, position.reason = BlackWins, Checkmate
+	} else {
+		position.status, position.reason = WhiteWins, Checkmate
+	}
+}
+
+func (position *Position) updateStatus() {
+	if position.countValidMoves() == 0 {
+		position.updateNoMovesStatus()
+	} else if position.halfmoveClock >= 150 {
+		position.status, position.reason = Draw, SeventyFiveMoveRule
+	} else if position.countRepetitions() >= 5 {
+		position.status, position.reason = Draw, FivefoldRepetition
+	} else if position.board.insufficientMaterial() {
+		position.status, position.reason = Draw, InsufficientMaterial
+	}
+}
+
+func (position *Position) DrawClaim() Reason {
+	if position.halfmoveClock >= 100 {
+		return FiftyMoveRule
+	}
+	if position.countRepetitions() >= 3 {
+		return ThreefoldRepetition
+	}
+	return NoReason
+}
+
+func (position *Position) ClaimDraw() error {
+	if position.status != Ongoing {
+		return gameOverError(position.status, position.reason)
+	}
+	reason := position.DrawClaim()
+	if reason == NoReason {
+		return fmt.Errorf("No draw can be claimed.")
+	}
+	position.status, position.reason = Draw, reason
+	return nil
+}
+
+func (position *Position) enPassantTarget() (Square, bool) {
+	if position.enPassant == nil {
+		return Square{}, false
+	}
+	ep := *position.enPassant
+	for _, fileDiff := range []int{-1, 1} {
+		from := Square{ep.file + fileDiff, ep.row - 1}
+		if position.turn == Black {
+			from.row = ep.row + 1
+		}
+		if !withinBounds(from) || !position.validateEnPassant(from, ep) {
+			continue
+		}
+		if isCheckedAfter, _ := position.board.kingIsCheckedAfterEnPassant(from, ep); !isCheckedAfter {
+			return ep, true
+		}
+	}
+	return Square{}, false
+}
+
+func (position *Position) countRepetitions() (count int) {
+	key := position.Hash()
+	for _, k := range position.repetitions {
+		if k == key {
+			count++
+		}
+	}
+	return
+}
+
+func (board *board) insufficientMaterial() bool {
+	if board.bitboardOf(wqueen)|board.bitboardOf(bqueen)|board.bitboardOf(wrook)|board.bitboardOf(brook)|
+		board.bitboardOf(wpawn)|board.bitboardOf(bpawn) != 0 {
+		return false
+	}
+	knights := board.bitboardOf(wknight) | board.bitboardOf(bknight)
+	bishops := board.bitboardOf(wbishop) | board.bitboardOf(bbishop)
+	if knights.count()+bishops.count() <= 1 {
+		return true
+	}
+	return knights == 0 && (bishops&lightSquares == 0 || bishops&^lightSquares == 0)
+}
+
+func (position *Position) InCheck() bool {
+	king, err := position.board.findKingOf(position.turn)
+	if err != nil {
+		return false
+	}
+	return position.board.squareAttackedByPlayer(king, !position.turn)
+}
+
+func (position *Position) validateCastling(from, to Square) bool {
+	var board *board = &(position.board)
+	var row int
+	var kingside, queenside castling
+	var rook Piece
+	var opponent Color
+	switch board.at(from) {
+	case wking:
+		row, kingside, queenside, rook, opponent = _1, whiteKingside, whiteQueenside, wrook, Black
+	case bking:
+		row, kingside, queenside, rook, opponent = _8, blackKingside, blackQueenside, brook, White
+	default:
+		return false
+	}
+	if from != (Square{_e, row}) || to.row != row {
+		return false
+	}
+	var right castling
+	switch to.file {
+	case _g:
+		right = kingside
+	case _c:
+		right = queenside
+	default:
+		return false
+	}
+	if position.castling&right == 0 {
+		return false
+	}
+	rookFrom, _ := castlingRookSquares(to)
+	if board.at(rookFrom) != rook {
+		return false
+	}
+	dir := 1
+	if rookFrom.file < from.file {
+		dir = -1
+	}
+	for file := from.file + dir; file != rookFrom.file; file += dir {
+		if board.at(Square{file, row}) != empty {
+			return false
+		}
+	}
+	for file := from.file; file != to.file+dir; file += dir {
+		if board.squareAttackedByPlayer(Square{file, row}, opponent) {
+			return false
+		}
+	}
+	return true
+}
+
+func (position *Position) validateEnPassant(from, to Square) bool {
+	if position.enPassant == nil || to != *position.enPassant {
+		return false
+	}
+	var board *board = &(position.board)
+	var dir int
+	var opponentPawn Piece
+	switch board.at(from) {
+	case wpawn:
+		dir, opponentPawn = 1, bpawn
+	case bpawn:
+		dir, opponentPawn = -1, wpawn
+	default:
+		return false
+	}
+	return abs(to.file-from.file) == 1 && to.row-from.row == dir &&
+		board.at(to) == empty && board.at(Square{to.file, from.row}) == opponentPawn
+}
+
+func isPromotion(piece Piece, to Square) bool {
+	return (piece == wpawn && to.row == _8) || (piece == bpawn && to.row == _1)
+}
+
+func promotionPieces(player Color) []Piece {
+	if player == White {
+		return []Piece{wqueen, wrook, wbishop, wknight}
+	}
+	return []Piece{bqueen, brook, bbishop, bknight}
+}
+
+func castlingRookSquares(kingTo Square) (from, to Square) {
+	if kingTo.file == _g {
+		return Square{_h, kingTo.row}, Square{_f, kingTo.row}
+	}
+	return Square{_a, kingTo.row}, Square{_d, kingTo.row}
+}
+
+func (position *Position) updateCastling(from, to Square, piece Piece) {
+	switch piece {
+	case wking:
+		position.castling &^= whiteKingside | whiteQueenside
+	case bking:
+		position.castling &^= blackKingside | blackQueenside
+	}
+	for _, sq := range []Square{from, to} {
+		switch sq {
+		case Square{_h, _1}:
+			position.castling &^= whiteKingside
+		case Square{_a, _1}:
+			position.castling &^= whiteQueenside
+		case Square{_h, _8}:
+			position.castling &^= blackKingside
+		case Square{_a, _8}:
+			position.castling &^= blackQueenside
+		}
+	}
+}
+
+func (board *board) validateMove(from, to Square) bool {
+	piece, target := board.at(from), board.at(to)
+	if piece == empty || target != empty && target.Color() == piece.Color() {
+		return false
+	}
+	if piece.Kind() != Pawn {
+		return board.attacks(from, board.all())&to.bit() != 0
+	}
+	if from.row == _1 || from.row == _8 {
+		panic(fmt.Sprintf("Impossible pawn position: %v", from))
+	}
+	if target == empty {
+		return board.pawnPushes(from)&to.bit() != 0
+	}
+	return board.attacks(from, board.all())&to.bit() != 0
+}
+
+func withinBounds(sq Square) bool {
+	return _a <= sq.file && sq.file <= _h && _1 <= sq.row && sq.row <= _8
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func parseMove(s string) (from, to Square, promotion Piece, err error) {
+	regex := regexp.MustCompile(`^[a-h][1-8]-[a-h][1-8](=[QRBN])?$`)
+	if !regex.MatchString(s) {
+		return Square{}, Square{}, empty, fmt.Errorf("Move %q does not match format", s)
+	}
+	s, suffix, _ := strings.Cut(s, "=")
+	switch suffix {
+	case "Q":
+		promotion = wqueen
+	case "R":
+		promotion = wrook
+	case "B":
+		promotion = wbishop
+	case "N":
+		promotion = wknight
+	default:
+		promotion = empty
+	}
+	squareStrings := strings.Split(s, "-")
+	if len(squareStrings) != 2 {
+		panic("Size of parsed squares is not 2.")
+	}
+	squareRunes := make([][]rune, 2)
+	squareRunes[0] = []rune(squareStrings[0])
+	squareRunes[1] = []rune(squareStrings[1])
+	from = Square{int(squareRunes[0][0] - fileUnicodeOffset), int(squareRunes[0][1] - rowUnicodeOffset)}
+	to = Square{int(squareRunes[1][0] - fileUnicodeOffset), int(squareRunes[1][1] - rowUnicodeOffset)}
+	return from, to, promotion, nil
+}
+
+func parseUCIMove(s string) (from, to Square, promotion Piece, err error) {
+	regex := regexp.MustCompile(`^[a-h][1-8][a-h][1-8][qrbn]?$`)
+	if !regex.MatchString(s) {
+		return Square{}, Square{}, empty, fmt.Errorf("Move %q does not match UCI format", s)
+	}
+	from, _ = ParseSquare(s[0:2])
+	to, _ = ParseSquare(s[2:4])
+	promotion = empty
+	if len(s) == 5 {
+		promotion = fenPieces[rune(s[4])]
+	}
+	return from, to, promotion, nil
+}
+
+func (p Color) String() string {
+	if p == White {
+		return "white"
+	}
+	return "black"
+}
+
+func (sq Square) File() int {
+	return sq.file
+}
+
+func (sq Square) Rank() int {
+	return sq.row
+}
+
+func NewSquare(file, rank int) (Square, error) {
+	sq := Square{file, rank}
+	if !withinBounds(sq) {
+		return Square{}, fmt.Errorf("%w File %d and rank %d must be between 0 and 7.", ErrOutOfBounds, file, rank)
+	}
+	return sq, nil
+}
+
+func (sq Square) String() string {
+	return fmt.Sprintf("%c%c", sq.file+fileUnicodeOffset, sq.row+rowUnicodeOffset)
+}
+
+func (s Status) String() string {
+	switch s {
+	case WhiteWins:
+		return "white wins"
+	case BlackWins:
+		return "black wins"
+	case Draw:
+		return "draw"
+	}
+	return "ongoing"
+}
+
+func (r Reason) String() string {
+	switch r {
+	case Checkmate:
+		return "checkmate"
+	case Stalemate:
+		return "stalemate"
+	case FiftyMoveRule:
+		return "fifty-move rule"
+	case SeventyFiveMoveRule:
+		return "seventy-five-move rule"
+	case ThreefoldRepetition:
+		return "threefold repetition"
+	case FivefoldRepetition:
+		return "fivefold repetition"
+	case InsufficientMaterial:
+		return "insufficient material"
+	}
+	return "no reason"
+}
@@ -0,0 +1,2241 @@
+package chess
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestValidateMoveTargetSameColor(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wking)
+	board.set(Square{_e, _5}, wqueen)
+	from := Square{_e, _4}
+	to := Square{_e, _5}
+	legal := board.validateMove(from, to)
+	if legal {
+		t.Error("Move to a square occupied by a piece of the same color should be illegal but is legal.")
+	}
+}
+
+func TestValidateMoveKingLegalEmpty(t *testing.T) {
+	var board board
+	board.clear()
+	pieces := []Piece{wking, bking}
+	for _, piece := range pieces {
+		board.set(Square{_e, _4}, piece)
+		from := Square{_e, _4}
+		tos := []Square{
+			{_d, _3},
+			{_e, _3},
+			{_f, _3},
+			{_d, _4},
+			{_f, _4},
+			{_d, _5},
+			{_e, _5},
+			{_f, _5},
+		}
+		for _, to := range tos {
+			legal := board.validateMove(from, to)
+			if !legal {
+				t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+			}
+		}
+	}
+}
+
+func TestValidateMoveKingIllegalDistance(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wking)
+	from := Square{_e, _4}
+	tos := []Square{
+		{_e, _2},
+		{_e, _6},
+		{_c, _4},
+		{_g, _4},
+		{_g, _6},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if legal {
+			t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveRookLegalEmpty(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wrook)
+	from := Square{_e, _4}
+	tos := []Square{
+		{_a, _4},
+		{_b, _4},
+		{_c, _4},
+		{_d, _4},
+		{_f, _4},
+		{_g, _4},
+		{_h, _4},
+		{_e, _1},
+		{_e, _2},
+		{_e, _3},
+		{_e, _5},
+		{_e, _6},
+		{_e, _7},
+		{_e, _8},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if !legal {
+			t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveRookIllegalDiagonal(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wrook)
+	from := Square{_e, _4}
+	to := Square{_f, _5}
+	legal := board.validateMove(from, to)
+	if legal {
+		t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+	}
+}
+
+func TestValidateMoveRookIllegalObstructed(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wrook)
+	board.set(Square{_e, _7}, wknight)
+	board.set(Square{_e, _2}, wknight)
+	board.set(Square{_b, _4}, bbishop)
+	board.set(Square{_g, _4}, brook)
+	from := Square{_e, _4}
+	tos := []Square{
+		{_e, _8},
+		{_e, _1},
+		{_a, _4},
+		{_h, _4},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if legal {
+			t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveBishopLegalEmpty(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wbishop)
+	from := Square{_e, _4}
+	tos := []Square{
+		{_b, _1},
+		{_c, _2},
+		{_d, _3},
+		{_a, _8},
+		{_b, _7},
+		{_c, _6},
+		{_d, _5},
+		{_f, _5},
+		{_g, _6},
+		{_h, _7},
+		{_f, _3},
+		{_g, _2},
+		{_h, _1},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if !legal {
+			t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveBishopIllegalOrthogonal(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wbishop)
+	from := Square{_e, _4}
+	to := Square{_e, _3}
+	legal := board.validateMove(from, to)
+	if legal {
+		t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+	}
+}
+
+func TestValidateMoveBishopIllegalObstructed(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wbishop)
+	board.set(Square{_b, _7}, wknight)
+	board.set(Square{_c, _2}, wknight)
+	board.set(Square{_g, _6}, bbishop)
+	board.set(Square{_g, _2}, brook)
+	from := Square{_e, _4}
+	tos := []Square{
+		{_a, _8},
+		{_b, _1},
+		{_h, _7},
+		{_h, _1},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if legal {
+			t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveQueenLegalEmpty(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wqueen)
+	from := Square{_e, _4}
+	tos := []Square{
+		{_a, _4},
+		{_b, _4},
+		{_c, _4},
+		{_d, _4},
+		{_f, _4},
+		{_g, _4},
+		{_h, _4},
+		{_e, _1},
+		{_e, _2},
+		{_e, _3},
+		{_e, _5},
+		{_e, _6},
+		{_e, _7},
+		{_e, _8},
+		{_b, _1},
+		{_c, _2},
+		{_d, _3},
+		{_a, _8},
+		{_b, _7},
+		{_c, _6},
+		{_d, _5},
+		{_f, _5},
+		{_g, _6},
+		{_h, _7},
+		{_f, _3},
+		{_g, _2},
+		{_h, _1},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if !legal {
+			t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveQueenIllegal(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wqueen)
+	from := Square{_e, _4}
+	to := Square{_f, _2}
+	legal := board.validateMove(from, to)
+	if legal {
+		t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+	}
+}
+
+func TestValidateMoveQueenIllegalObstructed(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wqueen)
+	board.set(Square{_b, _7}, wknight)
+	board.set(Square{_c, _2}, wknight)
+	board.set(Square{_g, _6}, bbishop)
+	board.set(Square{_g, _2}, brook)
+	board.set(Square{_e, _7}, wknight)
+	board.set(Square{_e, _2}, wknight)
+	board.set(Square{_b, _4}, bbishop)
+	board.set(Square{_g, _4}, brook)
+	from := Square{_e, _4}
+	tos := []Square{
+		{_a, _8},
+		{_b, _1},
+		{_h, _7},
+		{_h, _1},
+		{_e, _8},
+		{_e, _1},
+		{_a, _4},
+		{_h, _4},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if legal {
+			t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveKnightLegalEmpty(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wknight)
+	from := Square{_e, _4}
+	tos := []Square{
+		{_c, _3},
+		{_c, _5},
+		{_d, _2},
+		{_d, _6},
+		{_f, _2},
+		{_f, _6},
+		{_g, _3},
+		{_g, _5},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if !legal {
+			t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveKnightIllegal(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wknight)
+	from := Square{_e, _4}
+	to := Square{_e, _5}
+	legal := board.validateMove(from, to)
+	if legal {
+		t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+	}
+}
+
+func TestValidateMoveWhitePawnLegalStartingPos(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _2}, wpawn)
+	from := Square{_e, _2}
+	tos := []Square{
+		{_e, _3},
+		{_e, _4},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if !legal {
+			t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveWhitePawnLegalStandard(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _3}, wpawn)
+	from := Square{_e, _3}
+	to := Square{_e, _4}
+	legal := board.validateMove(from, to)
+	if !legal {
+		t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+	}
+}
+
+func TestValidateMoveWhitePawnLegalTaking(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wpawn)
+	board.set(Square{_d, _5}, bpawn)
+	board.set(Square{_f, _5}, bknight)
+	from := Square{_e, _4}
+	tos := []Square{
+		{_d, _5},
+		{_f, _5},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if !legal {
+			t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveWhitePawnIllegal(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _2}, wpawn)
+	from := Square{_e, _2}
+	tos := []Square{
+		{_e, _1},
+		{_e, _5},
+		{_d, _2},
+		{_d, _3},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if legal {
+			t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveBlackPawnLegalStartingPos(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _7}, bpawn)
+	from := Square{_e, _7}
+	tos := []Square{
+		{_e, _6},
+		{_e, _5},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if !legal {
+			t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveBlackPawnLegalStandard(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _6}, bpawn)
+	from := Square{_e, _6}
+	to := Square{_e, _5}
+	legal := board.validateMove(from, to)
+	if !legal {
+		t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+	}
+}
+
+func TestValidateMoveBlackPawnLegalTaking(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _5}, bpawn)
+	board.set(Square{_d, _4}, wpawn)
+	board.set(Square{_f, _4}, wknight)
+	from := Square{_e, _5}
+	tos := []Square{
+		{_d, _4},
+		{_f, _4},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if !legal {
+			t.Errorf("Move from %v to %v should be legal but is illegal.", from, to)
+		}
+	}
+}
+
+func TestValidateMoveBlackPawnIllegal(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _7}, bpawn)
+	from := Square{_e, _7}
+	tos := []Square{
+		{_e, _8},
+		{_e, _4},
+		{_d, _7},
+		{_d, _6},
+	}
+	for _, to := range tos {
+		legal := board.validateMove(from, to)
+		if legal {
+			t.Errorf("Move from %v to %v should be illegal but is legal.", from, to)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerQueenWhite(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := White
+	attackers := []Square{
+		{_a, _4},
+		{_b, _4},
+		{_c, _4},
+		{_d, _4},
+		{_f, _4},
+		{_g, _4},
+		{_h, _4},
+		{_e, _1},
+		{_e, _2},
+		{_e, _3},
+		{_e, _5},
+		{_e, _6},
+		{_e, _7},
+		{_e, _8},
+		{_b, _1},
+		{_c, _2},
+		{_d, _3},
+		{_a, _8},
+		{_b, _7},
+		{_c, _6},
+		{_d, _5},
+		{_f, _5},
+		{_g, _6},
+		{_h, _7},
+		{_f, _3},
+		{_g, _2},
+		{_h, _1},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, wqueen)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerQueenBlack(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := Black
+	attackers := []Square{
+		{_a, _4},
+		{_b, _4},
+		{_c, _4},
+		{_d, _4},
+		{_f, _4},
+		{_g, _4},
+		{_h, _4},
+		{_e, _1},
+		{_e, _2},
+		{_e, _3},
+		{_e, _5},
+		{_e, _6},
+		{_e, _7},
+		{_e, _8},
+		{_b, _1},
+		{_c, _2},
+		{_d, _3},
+		{_a, _8},
+		{_b, _7},
+		{_c, _6},
+		{_d, _5},
+		{_f, _5},
+		{_g, _6},
+		{_h, _7},
+		{_f, _3},
+		{_g, _2},
+		{_h, _1},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, bqueen)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerRookWhite(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := White
+	attackers := []Square{
+		{_a, _4},
+		{_b, _4},
+		{_c, _4},
+		{_d, _4},
+		{_f, _4},
+		{_g, _4},
+		{_h, _4},
+		{_e, _1},
+		{_e, _2},
+		{_e, _3},
+		{_e, _5},
+		{_e, _6},
+		{_e, _7},
+		{_e, _8},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, wrook)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerRookBlack(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := Black
+	attackers := []Square{
+		{_a, _4},
+		{_b, _4},
+		{_c, _4},
+		{_d, _4},
+		{_f, _4},
+		{_g, _4},
+		{_h, _4},
+		{_e, _1},
+		{_e, _2},
+		{_e, _3},
+		{_e, _5},
+		{_e, _6},
+		{_e, _7},
+		{_e, _8},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, brook)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerRookFalseDiagonal(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := Black
+	attackers := []Square{
+		{_b, _1},
+		{_c, _2},
+		{_d, _3},
+		{_a, _8},
+		{_b, _7},
+		{_c, _6},
+		{_d, _5},
+		{_f, _5},
+		{_g, _6},
+		{_h, _7},
+		{_f, _3},
+		{_g, _2},
+		{_h, _1},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, brook)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if attacked {
+			t.Errorf("Square %v should not be attacked by %v but is.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerBishopWhite(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := White
+	attackers := []Square{
+		{_b, _1},
+		{_c, _2},
+		{_d, _3},
+		{_a, _8},
+		{_b, _7},
+		{_c, _6},
+		{_d, _5},
+		{_f, _5},
+		{_g, _6},
+		{_h, _7},
+		{_f, _3},
+		{_g, _2},
+		{_h, _1},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, wbishop)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerBishopBlack(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := Black
+	attackers := []Square{
+		{_b, _1},
+		{_c, _2},
+		{_d, _3},
+		{_a, _8},
+		{_b, _7},
+		{_c, _6},
+		{_d, _5},
+		{_f, _5},
+		{_g, _6},
+		{_h, _7},
+		{_f, _3},
+		{_g, _2},
+		{_h, _1},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, bbishop)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerBishopFalseOrthogonal(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := Black
+	attackers := []Square{
+		{_a, _4},
+		{_b, _4},
+		{_c, _4},
+		{_d, _4},
+		{_f, _4},
+		{_g, _4},
+		{_h, _4},
+		{_e, _1},
+		{_e, _2},
+		{_e, _3},
+		{_e, _5},
+		{_e, _6},
+		{_e, _7},
+		{_e, _8},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, bbishop)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if attacked {
+			t.Errorf("Square %v should not be attacked by %v but is.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerBlackObstructed(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := Black
+	board.set(Square{_a, _8}, bqueen)
+	board.set(Square{_a, _4}, bqueen)
+	board.set(Square{_e, _8}, brook)
+	board.set(Square{_h, _1}, bbishop)
+	board.set(Square{_d, _4}, bknight)
+	board.set(Square{_d, _5}, wknight)
+	board.set(Square{_e, _5}, wknight)
+	board.set(Square{_f, _3}, bknight)
+	attacked := board.squareAttackedByPlayer(sq, player)
+	if attacked {
+		t.Errorf("Square %v should not be attacked but is.", sq)
+	}
+}
+
+func TestSquareAttackedByPlayerPawnWhite(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := White
+	attackers := []Square{
+		{_d, _3},
+		{_f, _3},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, wpawn)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerPawnBlack(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := Black
+	attackers := []Square{
+		{_d, _5},
+		{_f, _5},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, bpawn)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerPawnFalse(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := White
+	attackers := []Square{
+		{_e, _3},
+		{_e, _2},
+		{_d, _5},
+		{_f, _5},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, wpawn)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if attacked {
+			t.Errorf("Square %v should not be attacked by %v but is.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerKnightWhite(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := White
+	attackers := []Square{
+		{_d, _2},
+		{_f, _2},
+		{_c, _3},
+		{_g, _3},
+		{_c, _5},
+		{_g, _5},
+		{_d, _6},
+		{_f, _6},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, wknight)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerKnightBlack(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_e, _4}
+	player := Black
+	attackers := []Square{
+		{_d, _2},
+		{_f, _2},
+		{_c, _3},
+		{_g, _3},
+		{_c, _5},
+		{_g, _5},
+		{_d, _6},
+		{_f, _6},
+	}
+	for _, attacker := range attackers {
+		board.set(attacker, bknight)
+		attacked := board.squareAttackedByPlayer(sq, player)
+		board.set(attacker, empty)
+		if !attacked {
+			t.Errorf("Square %v should be attacked by %v but is not.", sq, attacker)
+		}
+	}
+}
+
+func TestSquareAttackedByPlayerBoundsCheck(t *testing.T) {
+	var board board
+	board.clear()
+	sq := Square{_a, _1}
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("Should not panic but does.\nMessage: %v", r)
+		}
+	}()
+	board.squareAttackedByPlayer(sq, Black)
+}
+
+func TestGenerateValidMovesKing(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wking)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_d, _3},
+			{_d, _4},
+			{_d, _5},
+			{_e, _3},
+			{_e, _5},
+			{_f, _3},
+			{_f, _4},
+			{_f, _5},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesKingEdge(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_h, _1}, wking)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_h, _1}: {
+			{_g, _1},
+			{_g, _2},
+			{_h, _2},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesKingNoMoves(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wking)
+	pos.board.set(Square{_f, _3}, wpawn)
+	pos.board.set(Square{_e, _6}, bking)
+	pos.board.set(Square{_e, _3}, bbishop)
+	pos.board.set(Square{_d, _1}, brook)
+	pos.board.set(Square{_f, _4}, bpawn)
+	pos.turn = White
+	got := pos.generateValidMoves()
+	from := Square{_e, _4}
+	_, exists := got[from]
+	if exists {
+		t.Errorf("Generated moves are wrong. Key for %v should not exist. Value: %v", from, got[from])
+	}
+}
+
+func TestGenerateValidMovesRook(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wrook)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_e, _1},
+			{_e, _2},
+			{_e, _3},
+			{_e, _5},
+			{_e, _6},
+			{_e, _7},
+			{_e, _8},
+			{_a, _4},
+			{_b, _4},
+			{_c, _4},
+			{_d, _4},
+			{_f, _4},
+			{_g, _4},
+			{_h, _4},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesRookObstructed(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wrook)
+	pos.board.set(Square{_e, _3}, wpawn)
+	pos.board.set(Square{_c, _4}, brook)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_e, _5},
+			{_e, _6},
+			{_e, _7},
+			{_e, _8},
+			{_c, _4},
+			{_d, _4},
+			{_f, _4},
+			{_g, _4},
+			{_h, _4},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesBishop(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wbishop)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_b, _1},
+			{_c, _2},
+			{_d, _3},
+			{_a, _8},
+			{_b, _7},
+			{_c, _6},
+			{_d, _5},
+			{_f, _5},
+			{_g, _6},
+			{_h, _7},
+			{_f, _3},
+			{_g, _2},
+			{_h, _1},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesBishopObstructed(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_g, _2}, wbishop)
+	pos.board.set(Square{_h, _1}, wbishop)
+	pos.board.set(Square{_c, _6}, brook)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_g, _2}: {
+			{_f, _1},
+			{_c, _6},
+			{_d, _5},
+			{_e, _4},
+			{_f, _3},
+			{_h, _3},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesQueen(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wqueen)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_e, _1},
+			{_e, _2},
+			{_e, _3},
+			{_e, _5},
+			{_e, _6},
+			{_e, _7},
+			{_e, _8},
+			{_a, _4},
+			{_b, _4},
+			{_c, _4},
+			{_d, _4},
+			{_f, _4},
+			{_g, _4},
+			{_h, _4},
+			{_b, _1},
+			{_c, _2},
+			{_d, _3},
+			{_a, _8},
+			{_b, _7},
+			{_c, _6},
+			{_d, _5},
+			{_f, _5},
+			{_g, _6},
+			{_h, _7},
+			{_f, _3},
+			{_g, _2},
+			{_h, _1},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesQueenObstructed(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wqueen)
+	pos.board.set(Square{_e, _3}, wpawn)
+	pos.board.set(Square{_c, _4}, brook)
+	pos.board.set(Square{_g, _6}, bbishop)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_e, _5},
+			{_e, _6},
+			{_e, _7},
+			{_e, _8},
+			{_c, _4},
+			{_d, _4},
+			{_f, _4},
+			{_g, _4},
+			{_h, _4},
+			{_b, _1},
+			{_c, _2},
+			{_d, _3},
+			{_a, _8},
+			{_b, _7},
+			{_c, _6},
+			{_d, _5},
+			{_f, _5},
+			{_g, _6},
+			{_f, _3},
+			{_g, _2},
+			{_h, _1},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesKnight(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wknight)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_d, _2},
+			{_f, _2},
+			{_c, _3},
+			{_g, _3},
+			{_c, _5},
+			{_g, _5},
+			{_d, _6},
+			{_f, _6},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesKnightObstructed(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wknight)
+	pos.board.set(Square{_d, _2}, brook)
+	pos.board.set(Square{_g, _3}, wpawn)
+	pos.board.set(Square{_g, _4}, bbishop)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_d, _2},
+			{_f, _2},
+			{_c, _3},
+			{_c, _5},
+			{_g, _5},
+			{_d, _6},
+			{_f, _6},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesKnightEdge(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_h, _1}, wknight)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_h, _1}: {
+			{_f, _2},
+			{_g, _3},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesPawnWhite(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _2}, wpawn)
+	pos.board.set(Square{_d, _3}, bpawn)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _2}: {
+			{_d, _3},
+			{_e, _3},
+			{_e, _4},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesPawnWhiteNonStart(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _3}, wpawn)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _3}: {
+			{_e, _4},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesPawnWhiteObstructed(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _2}, wpawn)
+	pos.board.set(Square{_e, _3}, bpawn)
+	pos.turn = White
+	want := map[Square][]Square{}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesPawnWhiteEdge(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_h, _3}, wpawn)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_h, _3}: {
+			{_h, _4},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesPawnBlack(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _7}, bpawn)
+	pos.board.set(Square{_d, _6}, wpawn)
+	pos.turn = Black
+	want := map[Square][]Square{
+		{_e, _7}: {
+			{_d, _6},
+			{_e, _6},
+			{_e, _5},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesPawnBlackNonStart(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _6}, bpawn)
+	pos.turn = Black
+	want := map[Square][]Square{
+		{_e, _6}: {
+			{_e, _5},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesPawnBlackObstructed(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _7}, bpawn)
+	pos.board.set(Square{_e, _6}, wpawn)
+	pos.turn = Black
+	want := map[Square][]Square{}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesPawnBlackEdge(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_h, _4}, bpawn)
+	pos.turn = Black
+	want := map[Square][]Square{
+		{_h, _4}: {
+			{_h, _3},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesPawnDoublePushBlocksCheck(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_g, _1}, wking)
+	pos.board.set(Square{_b, _6}, bbishop)
+	pos.board.set(Square{_d, _2}, wpawn)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_g, _1}: {
+			{_f, _1},
+			{_g, _2},
+			{_h, _1},
+			{_h, _2},
+		},
+		{_d, _2}: {
+			{_d, _4},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesCheckedMoveAway(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wking)
+	pos.board.set(Square{_e, _6}, brook)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_d, _3},
+			{_d, _4},
+			{_d, _5},
+			{_f, _3},
+			{_f, _4},
+			{_f, _5},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestGenerateValidMovesCheckedTake(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, wking)
+	pos.board.set(Square{_h, _8}, bking)
+	pos.board.set(Square{_e, _5}, brook)
+	pos.board.set(Square{_c, _5}, wrook)
+	pos.board.set(Square{_d, _4}, wbishop)
+	pos.board.set(Square{_f, _4}, wpawn)
+	pos.board.set(Square{_d, _3}, wpawn)
+	pos.board.set(Square{_f, _3}, wknight)
+	pos.turn = White
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_e, _5},
+		},
+		{_c, _5}: {
+			{_e, _5},
+		},
+		{_d, _4}: {
+			{_e, _5},
+		},
+		{_f, _4}: {
+			{_e, _5},
+		},
+		{_f, _3}: {
+			{_e, _5},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestMoveApplied(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	from, to := Square{_e, _2}, Square{_e, _4}
+	err := pos.move(from, to, empty)
+	if err != nil {
+		t.Fatalf("Move from %v to %v should be legal but errors: %v", from, to, err)
+	}
+	if pos.board.at(Square{_e, _2}) != empty || pos.board.at(Square{_e, _4}) != wpawn {
+		t.Errorf("Move from %v to %v is not applied to the board.", from, to)
+	}
+	if pos.turn != Black {
+		t.Errorf("Turn should be %v after move but is %v.", Black, pos.turn)
+	}
+	want := []move{{from: from, to: to, piece: wpawn, captured: empty, promotion: empty, san: "e4"}}
+	if !slices.Equal(want, pos.history) {
+		t.Errorf("History is wrong. Want %v but got %v.", want, pos.history)
+	}
+}
+
+func TestMoveCapture(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_d, _1}, wrook)
+	pos.board.set(Square{_d, _7}, bknight)
+	pos.turn = White
+	from, to := Square{_d, _1}, Square{_d, _7}
+	err := pos.move(from, to, empty)
+	if err != nil {
+		t.Fatalf("Move from %v to %v should be legal but errors: %v", from, to, err)
+	}
+	if pos.board.at(Square{_d, _7}) != wrook {
+		t.Errorf("Square %v should hold %v but holds %v.", to, wrook, pos.board.at(Square{_d, _7}))
+	}
+	if !slices.Equal([]Piece{bknight}, pos.captured) {
+		t.Errorf("Captured pieces are wrong. Want %v but got %v.", []Piece{bknight}, pos.captured)
+	}
+	if len(pos.history) != 1 || pos.history[0].captured != bknight {
+		t.Errorf("History does not record the captured piece: %v", pos.history)
+	}
+}
+
+func TestMoveRejected(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_e, _2}, wbishop)
+	pos.board.set(Square{_e, _7}, brook)
+	pos.board.set(Square{_a, _7}, bpawn)
+	pos.turn = White
+	moves := [][2]Square{
+		{{_a, _7}, {_a, _6}},
+		{{_c, _3}, {_c, _4}},
+		{{_e, _2}, {_e, _4}},
+		{{_e, _2}, {_d, _3}},
+	}
+	for _, m := range moves {
+		before := pos
+		err := pos.move(m[0], m[1], empty)
+		if err == nil {
+			t.Errorf("Move from %v to %v should error but does not.", m[0], m[1])
+		}
+		if pos.board != before.board || pos.turn != before.turn || len(pos.history) != 0 {
+			t.Errorf("Rejected move from %v to %v changes the position.", m[0], m[1])
+		}
+	}
+}
+
+func castlingPos() Position {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_a, _1}, wrook)
+	pos.board.set(Square{_h, _1}, wrook)
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_a, _8}, brook)
+	pos.board.set(Square{_h, _8}, brook)
+	pos.castling = whiteKingside | whiteQueenside | blackKingside | blackQueenside
+	pos.turn = White
+	return pos
+}
+
+func TestMoveCastling(t *testing.T) {
+	moves := []struct {
+		turn             Color
+		from, to         Square
+		rookFrom, rookTo Square
+	}{
+		{White, Square{_e, _1}, Square{_g, _1}, Square{_h, _1}, Square{_f, _1}},
+		{White, Square{_e, _1}, Square{_c, _1}, Square{_a, _1}, Square{_d, _1}},
+		{Black, Square{_e, _8}, Square{_g, _8}, Square{_h, _8}, Square{_f, _8}},
+		{Black, Square{_e, _8}, Square{_c, _8}, Square{_a, _8}, Square{_d, _8}},
+	}
+	for _, m := range moves {
+		pos := castlingPos()
+		pos.turn = m.turn
+		err := pos.move(m.from, m.to, empty)
+		if err != nil {
+			t.Errorf("Castling from %v to %v should be legal but errors: %v", m.from, m.to, err)
+			continue
+		}
+		king, rook := pos.board.at(m.to), pos.board.at(m.rookTo)
+		if (king != wking && king != bking) || (rook != wrook && rook != brook) ||
+			pos.board.at(m.rookFrom) != empty {
+			t.Errorf("Castling from %v to %v is not applied correctly.\n%v", m.from, m.to, pos.board.formatb())
+		}
+		var lost castling = whiteKingside | whiteQueenside
+		if m.turn == Black {
+			lost = blackKingside | blackQueenside
+		}
+		if pos.castling&lost != 0 {
+			t.Errorf("Castling rights of %v should be lost after castling but are %b.", m.turn, pos.castling)
+		}
+	}
+}
+
+func TestMoveCastlingIllegal(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(pos *Position)
+		to    Square
+	}{
+		{"no right", func(pos *Position) { pos.castling &^= whiteKingside }, Square{_g, _1}},
+		{"obstructed", func(pos *Position) { pos.board.set(Square{_b, _1}, wknight) }, Square{_c, _1}},
+		{"out of check", func(pos *Position) { pos.board.set(Square{_e, _5}, brook) }, Square{_g, _1}},
+		{"through check", func(pos *Position) { pos.board.set(Square{_f, _5}, brook) }, Square{_g, _1}},
+		{"into check", func(pos *Position) { pos.board.set(Square{_c, _5}, brook) }, Square{_c, _1}},
+		{"rook missing", func(pos *Position) { pos.board.set(Square{_h, _1}, empty) }, Square{_g, _1}},
+	}
+	for _, test := range tests {
+		pos := castlingPos()
+		test.setup(&pos)
+		from := Square{_e, _1}
+		if err := pos.move(from, test.to, empty); err == nil {
+			t.Errorf("Castling from %v to %v (%s) should error but does not.", from, test.to, test.name)
+		}
+	}
+}
+
+func TestMoveCastlingRightsLost(t *testing.T) {
+	pos := castlingPos()
+	pos.board.set(Square{_b, _2}, wbishop)
+	moves := [][2]Square{
+		{{_h, _1}, {_h, _2}},
+		{{_a, _8}, {_a, _7}},
+		{{_b, _2}, {_h, _8}},
+	}
+	for _, m := range moves {
+		if err := pos.move(m[0], m[1], empty); err != nil {
+			t.Fatalf("Move from %v to %v should be legal but errors: %v", m[0], m[1], err)
+		}
+	}
+	if pos.castling != whiteQueenside {
+		t.Errorf("Castling rights should be %b but are %b.", whiteQueenside, pos.castling)
+	}
+}
+
+func TestGenerateValidMovesCastling(t *testing.T) {
+	pos := castlingPos()
+	pos.board.set(Square{_f, _8}, brook)
+	pos.board.set(Square{_h, _8}, empty)
+	got := pos.generateValidMoves()
+	from := Square{_e, _1}
+	if !slices.Contains(got[from], Square{_c, _1}) {
+		t.Errorf("Generated moves for %v should contain castling to %v but are %v.", from, Square{_c, _1}, got[from])
+	}
+	if slices.Contains(got[from], Square{_g, _1}) {
+		t.Errorf("Generated moves for %v should not contain castling through check to %v.", from, Square{_g, _1})
+	}
+}
+
+func TestMoveEnPassant(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_e, _5}, wpawn)
+	pos.board.set(Square{_d, _7}, bpawn)
+	pos.turn = Black
+	if err := pos.move(Square{_d, _7}, Square{_d, _5}, empty); err != nil {
+		t.Fatal(err)
+	}
+	if pos.enPassant == nil || *pos.enPassant != (Square{_d, _6}) {
+		t.Fatalf("En passant square should be %v but is %v.", Square{_d, _6}, pos.enPassant)
+	}
+	from, to := Square{_e, _5}, Square{_d, _6}
+	if err := pos.move(from, to, empty); err != nil {
+		t.Fatalf("En passant from %v to %v should be legal but errors: %v", from, to, err)
+	}
+	if pos.board.at(Square{_d, _6}) != wpawn || pos.board.at(Square{_d, _5}) != empty || pos.board.at(Square{_e, _5}) != empty {
+		t.Errorf("En passant from %v to %v is not applied correctly.\n%v", from, to, pos.board.formatb())
+	}
+	if !slices.Equal([]Piece{bpawn}, pos.captured) || !pos.history[1].enPassant {
+		t.Errorf("En passant capture is not recorded: %v, %v", pos.history, pos.captured)
+	}
+	if pos.enPassant != nil {
+		t.Errorf("En passant square should be cleared but is %v.", pos.enPassant)
+	}
+}
+
+func TestMoveEnPassantExpired(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_d, _4}, bpawn)
+	pos.board.set(Square{_c, _2}, wpawn)
+	pos.turn = White
+	moves := [][2]Square{
+		{{_c, _2}, {_c, _4}},
+		{{_e, _8}, {_e, _7}},
+		{{_e, _1}, {_e, _2}},
+	}
+	for _, m := range moves {
+		if err := pos.move(m[0], m[1], empty); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from, to := Square{_d, _4}, Square{_c, _3}
+	if err := pos.move(from, to, empty); err == nil {
+		t.Errorf("En passant from %v to %v should error after the next move but does not.", from, to)
+	}
+}
+
+func TestMoveEnPassantExposesKing(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_a, _5}, wking)
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_h, _5}, brook)
+	pos.board.set(Square{_d, _5}, wpawn)
+	pos.board.set(Square{_e, _5}, bpawn)
+	pos.enPassant = &Square{_e, _6}
+	pos.turn = White
+	from, to := Square{_d, _5}, Square{_e, _6}
+	if err := pos.move(from, to, empty); err == nil {
+		t.Errorf("En passant from %v to %v exposes the king and should error but does not.", from, to)
+	}
+	if moves := pos.generateValidMoves(); slices.Contains(moves[from], to) {
+		t.Errorf("Generated moves for %v should not contain %v but are %v.", from, to, moves[from])
+	}
+}
+
+func TestGenerateValidMovesEnPassant(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _4}, bpawn)
+	pos.board.set(Square{_d, _4}, wpawn)
+	pos.enPassant = &Square{_d, _3}
+	pos.turn = Black
+	want := map[Square][]Square{
+		{_e, _4}: {
+			{_e, _3},
+			{_d, _3},
+		},
+	}
+	got := pos.generateValidMoves()
+	if !maps.EqualFunc(want, got, equivalent) {
+		t.Errorf("Generated moves are wrong. Want %v but got %v.", want, got)
+	}
+}
+
+func TestMovePromotion(t *testing.T) {
+	tests := []struct {
+		turn      Color
+		from, to  Square
+		promotion Piece
+		want      Piece
+	}{
+		{White, Square{_b, _7}, Square{_b, _8}, wqueen, wqueen},
+		{White, Square{_b, _7}, Square{_a, _8}, wknight, wknight},
+		{Black, Square{_g, _2}, Square{_g, _1}, wrook, brook},
+		{Black, Square{_g, _2}, Square{_h, _1}, bbishop, bbishop},
+	}
+	for _, test := range tests {
+		var pos Position
+		pos.board.clear()
+		pos.board.set(Square{_e, _1}, wking)
+		pos.board.set(Square{_e, _8}, bking)
+		pos.board.set(Square{_b, _7}, wpawn)
+		pos.board.set(Square{_a, _8}, brook)
+		pos.board.set(Square{_g, _2}, bpawn)
+		pos.board.set(Square{_h, _1}, wknight)
+		pos.turn = test.turn
+		if err := pos.move(test.from, test.to, test.promotion); err != nil {
+			t.Errorf("Promotion from %v to %v should be legal but errors: %v", test.from, test.to, err)
+			continue
+		}
+		if got := pos.board.at(test.to); got != test.want {
+			t.Errorf("Square %v should hold %v after promotion but holds %v.", test.to, test.want, got)
+		}
+		if pos.history[0].promotion != test.want {
+			t.Errorf("History does not record promotion to %v: %v", test.want, pos.history)
+		}
+	}
+}
+
+func TestMovePromotionIllegal(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_b, _7}, wpawn)
+	pos.board.set(Square{_c, _2}, wpawn)
+	pos.turn = White
+	tests := []struct {
+		from, to  Square
+		promotion Piece
+	}{
+		{Square{_b, _7}, Square{_b, _8}, empty},
+		{Square{_b, _7}, Square{_b, _8}, wking},
+		{Square{_b, _7}, Square{_b, _8}, wpawn},
+		{Square{_c, _2}, Square{_c, _3}, wqueen},
+	}
+	for _, test := range tests {
+		if err := pos.move(test.from, test.to, test.promotion); err == nil {
+			t.Errorf("Move from %v to %v with promotion %v should error but does not.", test.from, test.to,
+				test.promotion)
+		}
+	}
+}
+
+func TestValidMovesPromotion(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_b, _7}, wpawn)
+	pos.board.set(Square{_a, _8}, brook)
+	pos.turn = White
+	var got []move
+	for _, m := range pos.validMoves() {
+		got = append(got, move{from: m.from, to: m.to, promotion: m.promotion})
+	}
+	var want []move
+	for _, to := range []Square{{_a, _8}, {_b, _8}} {
+		for _, promotion := range []Piece{wqueen, wrook, wbishop, wknight} {
+			want = append(want, move{from: Square{_b, _7}, to: to, promotion: promotion})
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Valid moves are wrong. Want %v but got %v.", want, got)
+	}
+	for _, m := range want {
+		if !slices.Contains(got, m) {
+			t.Errorf("Valid moves should contain %v but do not: %v", m, got)
+		}
+	}
+}
+
+func TestMoveCheckmate(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	moves := [][2]Square{
+		{{_f, _2}, {_f, _3}},
+		{{_e, _7}, {_e, _5}},
+		{{_g, _2}, {_g, _4}},
+		{{_d, _8}, {_h, _4}},
+	}
+	for _, m := range moves {
+		if err := pos.move(m[0], m[1], empty); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pos.status != BlackWins || pos.reason != Checkmate {
+		t.Errorf("Game should be over with %v by %v but is %v by %v.", BlackWins, Checkmate, pos.status, pos.reason)
+	}
+	if err := pos.move(Square{_a, _2}, Square{_a, _3}, empty); err == nil {
+		t.Error("Move after the game is over should error but does not.")
+	}
+}
+
+func TestMoveStalemate(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_h, _8}, bking)
+	pos.board.set(Square{_f, _7}, wking)
+	pos.board.set(Square{_g, _5}, wqueen)
+	pos.turn = White
+	if err := pos.move(Square{_g, _5}, Square{_g, _6}, empty); err != nil {
+		t.Fatal(err)
+	}
+	if pos.status != Draw || pos.reason != Stalemate {
+		t.Errorf("Game should be over with %v by %v but is %v by %v.", Draw, Stalemate, pos.status, pos.reason)
+	}
+}
+
+func TestMoveCheckOngoing(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_a, _1}, wrook)
+	pos.turn = White
+	if err := pos.move(Square{_a, _1}, Square{_a, _8}, empty); err != nil {
+		t.Fatal(err)
+	}
+	if !pos.InCheck() {
+		t.Errorf("%v should be in check but is not.", pos.turn)
+	}
+	if pos.status != Ongoing {
+		t.Errorf("Game should be %v but is %v by %v.", Ongoing, pos.status, pos.reason)
+	}
+}
+
+func TestGenerateValidMovesPawnEdgeWithKing(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_a, _3}, wpawn)
+	pos.board.set(Square{_h, _3}, wpawn)
+	pos.turn = White
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("Should not panic but does.\nMessage: %v", r)
+		}
+	}()
+	pos.generateValidMoves()
+}
+
+func TestMoveThreefoldAndFivefoldRepetition(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	shuffle := [][2]Square{
+		{{_g, _1}, {_f, _3}},
+		{{_g, _8}, {_f, _6}},
+		{{_f, _3}, {_g, _1}},
+		{{_f, _6}, {_g, _8}},
+	}
+	for i := 1; i <= 4; i++ {
+		for _, m := range shuffle {
+			if err := pos.move(m[0], m[1], empty); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if i == 2 && pos.DrawClaim() != ThreefoldRepetition {
+			t.Errorf("Draw by %v should be claimable but is not.", ThreefoldRepetition)
+		}
+		if i < 4 && pos.status != Ongoing {
+			t.Errorf("Game should be %v after %d repetitions but is %v by %v.", Ongoing, i+1, pos.status, pos.reason)
+		}
+	}
+	if pos.status != Draw || pos.reason != FivefoldRepetition {
+		t.Errorf("Game should be over with %v by %v but is %v by %v.", Draw, FivefoldRepetition, pos.status,
+			pos.reason)
+	}
+}
+
+func TestMoveFiftyAndSeventyFiveMoveRule(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_a, _1}, wrook)
+	pos.turn = White
+	pos.halfmoveClock = 99
+	if err := pos.move(Square{_a, _1}, Square{_a, _2}, empty); err != nil {
+		t.Fatal(err)
+	}
+	if pos.DrawClaim() != FiftyMoveRule || pos.status != Ongoing {
+		t.Errorf("Draw by %v should be claimable but is not: %v by %v.", FiftyMoveRule, pos.status, pos.reason)
+	}
+	pos.halfmoveClock = 149
+	if err := pos.move(Square{_e, _8}, Square{_e, _7}, empty); err != nil {
+		t.Fatal(err)
+	}
+	if pos.status != Draw || pos.reason != SeventyFiveMoveRule {
+		t.Errorf("Game should be over with %v by %v but is %v by %v.", Draw, SeventyFiveMoveRule, pos.status,
+			pos.reason)
+	}
+}
+
+func TestMoveResetsHalfmoveClock(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	pos.halfmoveClock = 42
+	if err := pos.move(Square{_e, _2}, Square{_e, _4}, empty); err != nil {
+		t.Fatal(err)
+	}
+	if pos.halfmoveClock != 0 {
+		t.Errorf("Halfmove clock should be reset by a pawn move but is %d.", pos.halfmoveClock)
+	}
+}
+
+func TestClaimDraw(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	if err := pos.ClaimDraw(); err == nil || pos.status != Ongoing {
+		t.Error("Claiming a draw in the starting position should error but does not.")
+	}
+	pos.halfmoveClock = 100
+	if err := pos.ClaimDraw(); err != nil || pos.status != Draw || pos.reason != FiftyMoveRule {
+		t.Errorf("Claiming a draw should succeed but is %v by %v: %v", pos.status, pos.reason, err)
+	}
+}
+
+func TestInsufficientMaterial(t *testing.T) {
+	tests := []struct {
+		pieces map[Square]Piece
+		want   bool
+	}{
+		{map[Square]Piece{}, true},
+		{map[Square]Piece{{_c, _3}: wknight}, true},
+		{map[Square]Piece{{_c, _3}: bbishop}, true},
+		{map[Square]Piece{{_c, _1}: wbishop, {_f, _8}: bbishop, {_a, _3}: wbishop}, true},
+		{map[Square]Piece{{_c, _1}: wbishop, {_c, _8}: bbishop}, false},
+		{map[Square]Piece{{_c, _1}: wbishop, {_c, _3}: wknight}, false},
+		{map[Square]Piece{{_c, _3}: wknight, {_c, _4}: bknight}, false},
+		{map[Square]Piece{{_c, _3}: wpawn}, false},
+		{map[Square]Piece{{_c, _3}: brook}, false},
+	}
+	for _, test := range tests {
+		var board board
+		board.clear()
+		board.set(Square{_e, _1}, wking)
+		board.set(Square{_e, _8}, bking)
+		for sq, piece := range test.pieces {
+			board.set(sq, piece)
+		}
+		if got := board.insufficientMaterial(); got != test.want {
+			t.Errorf("Insufficient material should be %v but is %v.\n%v", test.want, got, board.formatb())
+		}
+	}
+}
+
+func TestMoveInsufficientMaterial(t *testing.T) {
+	var pos Position
+	pos.board.clear()
+	pos.board.set(Square{_e, _1}, wking)
+	pos.board.set(Square{_e, _8}, bking)
+	pos.board.set(Square{_e, _2}, bknight)
+	pos.turn = White
+	if err := pos.move(Square{_e, _1}, Square{_e, _2}, empty); err != nil {
+		t.Fatal(err)
+	}
+	if pos.status != Draw || pos.reason != InsufficientMaterial {
+		t.Errorf("Game should be over with %v by %v but is %v by %v.", Draw, InsufficientMaterial, pos.status,
+			pos.reason)
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	pos, err := ParseFEN("r3k2r/1P6/8/3pP3/8/8/8/R3K2R w KQkq d6 7 30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moves := []struct {
+		from, to  Square
+		promotion Piece
+	}{
+		{Square{_e, _5}, Square{_d, _6}, empty},
+		{Square{_e, _8}, Square{_g, _8}, empty},
+		{Square{_b, _7}, Square{_a, _8}, wqueen},
+		{Square{_f, _8}, Square{_a, _8}, empty},
+	}
+	var fens []string
+	for _, m := range moves {
+		fens = append(fens, pos.FEN())
+		if err := pos.move(m.from, m.to, m.promotion); err != nil {
+			t.Fatal(err)
+		}
+	}
+	final, captured := pos.FEN(), len(pos.captured)
+	for i := len(moves) - 1; i >= 0; i-- {
+		if err := pos.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		if got := pos.FEN(); got != fens[i] {
+			t.Errorf("Undoing move %d should give %q but gives %q.", i+1, fens[i], got)
+		}
+	}
+	if len(pos.history) != 0 || len(pos.captured) != 0 || len(pos.repetitions) != 1 {
+		t.Errorf("Undoing all moves should clear history, captured pieces and repetitions but does not: %v %v %d",
+			pos.history, pos.captured, len(pos.repetitions))
+	}
+	if err := pos.Undo(); err == nil {
+		t.Error("Undo without history should error but does not.")
+	}
+	for range moves {
+		if err := pos.Redo(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := pos.FEN(); got != final || len(pos.captured) != captured {
+		t.Errorf("Redoing all moves should give %q but gives %q.", final, got)
+	}
+	if err := pos.Redo(); err == nil {
+		t.Error("Redo without undone moves should error but does not.")
+	}
+}
+
+func TestUndoAfterCheckmate(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	for _, san := range []string{"f3", "e5", "g4", "Qh4#"} {
+		m, err := pos.parseSAN(san)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pos.move(m.from, m.to, m.promotion); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pos.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if pos.status != Ongoing || pos.turn != Black {
+		t.Errorf("Undoing checkmate should reopen the game for %v but is %v by %v.", Black, pos.status, pos.reason)
+	}
+	if err := pos.move(Square{_a, _7}, Square{_a, _6}, empty); err != nil {
+		t.Fatal(err)
+	}
+	if err := pos.Redo(); err == nil {
+		t.Error("Redo after a new move should error but does not.")
+	}
+}
+
+func TestParseMoveOk(t *testing.T) {
+	move := "e2-a5"
+	gotFrom, gotTo, gotPromotion, err := parseMove(move)
+	wantFrom, wantTo := Square{_e, _2}, Square{_a, _5}
+	if gotFrom != wantFrom || gotTo != wantTo || gotPromotion != empty || err != nil {
+		t.Errorf("Move %q is not parsed correctly. From: %v, To: %v, Promotion: %v, Error: %v", move, gotFrom,
+			gotTo, gotPromotion, err)
+	}
+}
+
+func TestParseMovePromotion(t *testing.T) {
+	moves := map[string]Piece{
+		"e7-e8=Q": wqueen,
+		"e7-e8=R": wrook,
+		"e7-e8=B": wbishop,
+		"d2-c1=N": wknight,
+	}
+	for move, want := range moves {
+		_, _, got, err := parseMove(move)
+		if got != want || err != nil {
+			t.Errorf("Move %q is not parsed correctly. Promotion: %v, Error: %v", move, got, err)
+		}
+	}
+}
+
+func TestParseMoveError(t *testing.T) {
+	moves := []string{
+		"e2-i5",
+		"e9-e5",
+		"xyz",
+		"ab-cd",
+		"##e2-e5##",
+		"e7-e8=K",
+		"e7-e8=",
+		"e7-e8Q",
+	}
+	for _, move := range moves {
+		_, _, _, err := parseMove(move)
+		if err == nil {
+			t.Errorf("Parsing move %q should error, but does not", move)
+		}
+	}
+}
+
+func TestParseUCIMove(t *testing.T) {
+	tests := []struct {
+		move      string
+		from, to  Square
+		promotion Piece
+	}{
+		{"e2e4", Square{_e, _2}, Square{_e, _4}, empty},
+		{"g8f6", Square{_g, _8}, Square{_f, _6}, empty},
+		{"e7e8q", Square{_e, _7}, Square{_e, _8}, bqueen},
+		{"a2a1n", Square{_a, _2}, Square{_a, _1}, bknight},
+	}
+	for _, test := range tests {
+		from, to, promotion, err := parseUCIMove(test.move)
+		if err != nil || from != test.from || to != test.to || promotion != test.promotion {
+			t.Errorf("Move %q is not parsed correctly. From: %v, To: %v, Promotion: %v, Error: %v", test.move, from,
+				to, promotion, err)
+		}
+	}
+}
+
+func TestParseUCIMoveError(t *testing.T) {
+	moves := []string{
+		"e2-e4",
+		"e2e9",
+		"e7e8Q",
+		"e7e8k",
+		"e2e4 ",
+		"0000",
+	}
+	for _, move := range moves {
+		if _, _, _, err := parseUCIMove(move); err == nil {
+			t.Errorf("Parsing move %q should error, but does not", move)
+		}
+	}
+}
+
+func TestMoveUCI(t *testing.T) {
+	tests := []struct {
+		move move
+		want string
+	}{
+		{move{from: Square{_e, _2}, to: Square{_e, _4}, promotion: empty}, "e2e4"},
+		{move{from: Square{_e, _1}, to: Square{_g, _1}}, "e1g1"},
+		{move{from: Square{_b, _7}, to: Square{_a, _8}, promotion: wknight}, "b7a8n"},
+		{move{from: Square{_d, _2}, to: Square{_d, _1}, promotion: bqueen}, "d2d1q"},
+	}
+	for _, test := range tests {
+		if got := test.move.packed().UCI(); got != test.want {
+			t.Errorf("UCI of %v should be %q but is %q.", test.move, test.want, got)
+		}
+	}
+}
+
+func TestFindKingOf(t *testing.T) {
+	var board board
+	board.clear()
+	board.set(Square{_e, _4}, wking)
+	board.set(Square{_e, _6}, bking)
+	_, wErr := board.findKingOf(White)
+	_, bErr := board.findKingOf(Black)
+	if wErr != nil || bErr != nil {
+		t.Error(wErr, bErr)
+	}
+}
+
+func TestFindKingOfErr(t *testing.T) {
+	var board board
+	board.clear()
+	_, err := board.findKingOf(White)
+	if err == nil {
+		t.Error("Missing king should error but does not")
+	}
+}
+
+func TestLegalMovesAndApply(t *testing.T) {
+	position := NewPosition()
+	moves := position.LegalMoves()
+	if len(moves) != 20 {
+		t.Errorf("Starting position should have 20 legal moves but has %v", len(moves))
+	}
+	for _, input := range []string{"e4", "e7-e5", "g1f3"} {
+		m, err := position.ParseMove(input)
+		if err != nil {
+			t.Fatalf("Move %q should parse but fails: %v", input, err)
+		}
+		if !slices.Contains(position.LegalMoves(), m) {
+			t.Errorf("Parsed move %v should be among the legal moves but is not", m)
+		}
+		if err := position.Apply(m); err != nil {
+			t.Fatalf("Move %q should apply but fails: %v", input, err)
+		}
+	}
+	want := "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"
+	if got := position.FEN(); got != want {
+		t.Errorf("FEN should be %q but is %q", want, got)
+	}
+	if position.Turn() != Black || position.Status() != Ongoing {
+		t.Errorf("Black should be to move in an ongoing game but turn is %v and status %v", position.Turn(), position.Status())
+	}
+	if got := position.At(Square{_f, _3}); got != wknight {
+		t.Errorf("Knight should be on f3 but found %v", got)
+	}
+	if err := position.Apply(newMove(Square{_e, _5}, Square{_e, _4}, empty, 0)); err == nil {
+		t.Error("Illegal move should not apply but does")
+	}
+}
+
+func TestNewSquare(t *testing.T) {
+	sq, err := NewSquare(_e, _4)
+	if err != nil || sq.String() != "e4" || sq.File() != 4 || sq.Rank() != 3 {
+		t.Errorf("Square at file 4 and rank 3 should be e4 but is %v (error %v)", sq, err)
+	}
+	parsed, _ := ParseSquare("h1")
+	if parsed.File() != 7 || parsed.Rank() != 0 {
+		t.Errorf("Square h1 should have file 7 and rank 0 but has %d and %d", parsed.File(), parsed.Rank())
+	}
+	for _, coords := range [][2]int{{-1, 0}, {0, 8}, {8, 3}} {
+		if _, err := NewSquare(coords[0], coords[1]); !errors.Is(err, ErrOutOfBounds) {
+			t.Errorf("Square at file %d and rank %d should be out of bounds but errors with %v", coords[0], coords[1], err)
+		}
+	}
+}
+
+func TestPositionClone(t *testing.T) {
+	play := func(position *Position, moves ...string) {
+		for _, input := range moves {
+			m, err := position.ParseMove(input)
+			if err == nil {
+				err = position.Apply(m)
+			}
+			if err != nil {
+				t.Fatalf("Move %q should apply but fails: %v", input, err)
+			}
+		}
+	}
+	original := NewPosition()
+	play(original, "e4", "e5", "Nf3")
+	clone := original.Clone()
+	play(clone, "Nc6")
+	play(original, "d6")
+	if err := clone.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	want := "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"
+	if got := clone.FEN(); got != want {
+		t.Errorf("Clone after undo should be %q but is %q", want, got)
+	}
+	want = "rnbqkbnr/ppp2ppp/3p4/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 0 3"
+	if got := original.FEN(); got != want {
+		t.Errorf("Original should be %q but is %q", want, got)
+	}
+	if err := original.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := original.Redo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := original.FEN(); got != want {
+		t.Errorf("Original after undo and redo should be %q but is %q", want, got)
+	}
+}
+
+func TestPositionParseMovePromotion(t *testing.T) {
+	position, err := ParseFEN("8/4P3/8/8/8/8/k7/4K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range []string{"e8=N", "e7-e8=N", "e7e8n"} {
+		m, err := position.ParseMove(input)
+		if err != nil {
+			t.Fatalf("Move %q should parse but fails: %v", input, err)
+		}
+		if m.From() != (Square{_e, _7}) || m.To() != (Square{_e, _8}) || m.Promotion() != Knight {
+			t.Errorf("Move %q should be e7e8n but is %v", input, m)
+		}
+	}
+}
+
+func (position *Position) generateValidMoves() map[Square][]Square {
+	moves := make(map[Square][]Square)
+	for _, m := range position.validMoves() {
+		if !slices.Contains(moves[m.from], m.to) {
+			moves[m.from] = append(moves[m.from], m.to)
+		}
+	}
+	return moves
+}
+
+func equivalent(a, b []Square) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, sq := range a {
+		if !slices.Contains(b, sq) {
+			return false
+		}
+	}
+	return true
+}
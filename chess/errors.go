@@ -0,0 +1,213 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+var (
+	ErrGameOver         = errors.New("Game is over")
+	ErrOutOfBounds      = errors.New("Square is out of bounds.")
+	ErrSameSquare       = errors.New("Target square is same as origin square.")
+	ErrEmptySquare      = errors.New("Square is empty.")
+	ErrWrongTurn        = errors.New("Piece does not belong to the player to move.")
+	ErrOwnPiece         = errors.New("Target square is occupied by an own piece.")
+	ErrIllegalGeometry  = errors.New("Piece cannot move that way.")
+	ErrPathBlocked      = errors.New("Path is blocked.")
+	ErrNoCastlingRights = errors.New("Castling right is lost.")
+	ErrPinned           = errors.New("Piece is pinned.")
+	ErrInCheck          = errors.New("King is left in check.")
+	ErrKingAttacked     = errors.New("King moves onto an attacked square.")
+	ErrMissingPromotion = errors.New("Promotion piece is missing.")
+	ErrInvalidPromotion = errors.New("Promotion piece is invalid.")
+	ErrNotPromotion     = errors.New("Move is not a promotion.")
+)
+
+type MoveError struct {
+	Err        error
+	Piece      Piece
+	From       Square
+	To         Square
+	Promotion  Piece
+	King       Square
+	Cause      Square
+	CausePiece Piece
+}
+
+func (e *MoveError) Error() string {
+	piece, cause := pieceOn(e.Piece, e.From), pieceOn(e.CausePiece, e.Cause)
+	castling := e.Piece.Kind() == King && abs(e.To.file-e.From.file) == 2
+	switch {
+	case e.Err == ErrOutOfBounds:
+		return fmt.Sprintf("Square at file %d and row %d is out of bounds.", e.Cause.file, e.Cause.row)
+	case e.Err == ErrSameSquare:
+		return "Target square is same as origin square."
+	case e.Err == ErrEmptySquare:
+		return fmt.Sprintf("Square %v is empty!", e.From)
+	case e.Err == ErrWrongTurn:
+		return fmt.Sprintf("Not %v's turn!", e.Piece.Color())
+	case e.Err == ErrOwnPiece:
+		return fmt.Sprintf("%s cannot capture own %s.", capitalized(piece), cause)
+	case e.Err == ErrIllegalGeometry:
+		return fmt.Sprintf("%s cannot move to %v.", capitalized(piece), e.To)
+	case e.Err == ErrPathBlocked && castling:
+		return fmt.Sprintf("Castling from %v to %v is blocked by %s.", e.From, e.To, cause)
+	case e.Err == ErrPathBlocked:
+		return fmt.Sprintf("%s cannot move to %v, it is blocked by %s.", capitalized(piece), e.To, cause)
+	case e.Err == ErrNoCastlingRights:
+		return fmt.Sprintf("Castling from %v to %v is not allowed, the king or rook has already moved.", e.From, e.To)
+	case e.Err == ErrPinned:
+		return fmt.Sprintf("%s is pinned against king on %v by %s.", capitalized(piece), e.King, cause)
+	case e.Err == ErrInCheck && castling:
+		return fmt.Sprintf("Castling from %v to %v is not allowed, king on %v is in check by %s.", e.From, e.To,
+			e.King, cause)
+	case e.Err == ErrInCheck:
+		return fmt.Sprintf("Move from %v to %v leaves king on %v in check by %s.", e.From, e.To, e.King, cause)
+	case e.Err == ErrKingAttacked && castling:
+		return fmt.Sprintf("Castling from %v to %v is not allowed, %v is attacked by %s.", e.From, e.To, e.King,
+			cause)
+	case e.Err == ErrKingAttacked:
+		return fmt.Sprintf("%s cannot move to %v, it is attacked by %s.", capitalized(piece), e.To, cause)
+	case e.Err == ErrMissingPromotion:
+		return fmt.Sprintf("Move from %v to %v needs a promotion piece, e.g. %v-%v=Q.", e.From, e.To, e.From, e.To)
+	case e.Err == ErrInvalidPromotion && e.Promotion == empty:
+		return fmt.Sprintf("Move from %v to %v has an invalid promotion piece!", e.From, e.To)
+	case e.Err == ErrInvalidPromotion:
+		return fmt.Sprintf("Pawn cannot promote to %v!", e.Promotion.Kind())
+	case e.Err == ErrNotPromotion:
+		return fmt.Sprintf("Move from %v to %v is not a promotion!", e.From, e.To)
+	}
+	return e.Err.Error()
+}
+
+func (e *MoveError) Unwrap() error {
+	return e.Err
+}
+
+func pieceOn(piece Piece, sq Square) string {
+	return fmt.Sprintf("%v on %v", piece.Kind(), sq)
+}
+
+func capitalized(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func gameOverError(status Status, reason Reason) error {
+	return fmt.Errorf("%w: %v by %v.", ErrGameOver, status, reason)
+}
+
+func nearest(b bitboard, from int) int {
+	if b.first() > from {
+		return b.first()
+	}
+	return 63 - bits.LeadingZeros64(uint64(b))
+}
+
+func (board *board) moveError(from, to Square) error {
+	piece, target := board.at(from), board.at(to)
+	e := &MoveError{Err: ErrIllegalGeometry, Piece: piece, From: from, To: to}
+	i, j := from.index(), to.index()
+	reachable, push := board.attacks(from, 0)&to.bit() != 0, false
+	if piece.Kind() == Pawn {
+		dir, startRow := 1, _2
+		if piece.Color() == Black {
+			dir, startRow = -1, _7
+		}
+		steps := (to.row - from.row) * dir
+		push = to.file == from.file && (steps == 1 || steps == 2 && from.row == startRow)
+	}
+	if target != empty && target.Color() == piece.Color() {
+		if reachable || push {
+			e.Err, e.Cause, e.CausePiece = ErrOwnPiece, to, target
+		}
+		return e
+	}
+	if piece.Kind() == Pawn {
+		reachable = push
+		if target != empty && reachable && between[i][j]&board.all() == 0 {
+			e.Err, e.Cause, e.CausePiece = ErrPathBlocked, to, target
+			return e
+		}
+	}
+	if blockers := between[i][j] & board.all(); reachable && blockers != 0 {
+		k := nearest(blockers, i)
+		e.Err, e.Cause, e.CausePiece = ErrPathBlocked, squareAt(k), board.squares[k]
+	}
+	return e
+}
+
+func (position *Position) castlingError(from, to Square) error {
+	var board *board = &(position.board)
+	piece := board.at(from)
+	e := &MoveError{Err: ErrIllegalGeometry, Piece: piece, From: from, To: to}
+	row, kingside, queenside := _1, whiteKingside, whiteQueenside
+	if piece.Color() == Black {
+		row, kingside, queenside = _8, blackKingside, blackQueenside
+	}
+	if from != (Square{_e, row}) || to.row != row || (to.file != _g && to.file != _c) {
+		return e
+	}
+	right := kingside
+	if to.file == _c {
+		right = queenside
+	}
+	rookFrom, _ := castlingRookSquares(to)
+	if position.castling&right == 0 || board.at(rookFrom) != colored(wrook, piece.Color()) {
+		e.Err = ErrNoCastlingRights
+		return e
+	}
+	if blockers := between[from.index()][rookFrom.index()] & board.all(); blockers != 0 {
+		k := nearest(blockers, from.index())
+		e.Err, e.Cause, e.CausePiece = ErrPathBlocked, squareAt(k), board.squares[k]
+		return e
+	}
+	dir := 1
+	if to.file < from.file {
+		dir = -1
+	}
+	for file := from.file; file != to.file+dir; file += dir {
+		sq := Square{file, row}
+		if attackers := board.attackers(sq.index(), !piece.Color(), board.all(), 0); attackers != 0 {
+			e.Err, e.King = ErrKingAttacked, sq
+			if sq == from {
+				e.Err = ErrInCheck
+			}
+			e.Cause, e.CausePiece = squareAt(attackers.first()), board.squares[attackers.first()]
+			return e
+		}
+	}
+	return e
+}
+
+func (board *board) checkError(from, to Square, enPassant bool) error {
+	piece := board.at(from)
+	player := piece.Color()
+	e := &MoveError{Err: ErrInCheck, Piece: piece, From: from, To: to}
+	king := board.bitboardOf(colored(wking, player))
+	if piece.Kind() == King {
+		king = to.bit()
+		e.Err = ErrKingAttacked
+	}
+	captured := to.bit()
+	if enPassant {
+		captured = Square{to.file, from.row}.bit()
+	}
+	occupied := board.all()&^from.bit()&^captured | to.bit()
+	k := king.first()
+	attackers := board.attackers(k, !player, occupied, captured)
+	e.King = squareAt(k)
+	e.Cause, e.CausePiece = squareAt(attackers.first()), board.squares[attackers.first()]
+	if piece.Kind() == King {
+		return e
+	}
+	for attackers != 0 {
+		i := attackers.pop()
+		if between[k][i]&from.bit() != 0 {
+			e.Err, e.Cause, e.CausePiece = ErrPinned, squareAt(i), board.squares[i]
+			break
+		}
+	}
+	return e
+}
@@ -0,0 +1,114 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMoveErrors(t *testing.T) {
+	tests := []struct {
+		fen       string
+		from, to  Square
+		promotion Piece
+		want      error
+		message   string
+	}{
+		{StartingFEN, Square{_e, _2}, Square{_e, 8}, empty, ErrOutOfBounds,
+			"Square at file 4 and row 8 is out of bounds."},
+		{StartingFEN, Square{_e, _2}, Square{_e, _2}, empty, ErrSameSquare,
+			"Target square is same as origin square."},
+		{StartingFEN, Square{_e, _4}, Square{_e, _5}, empty, ErrEmptySquare, "Square e4 is empty!"},
+		{StartingFEN, Square{_e, _7}, Square{_e, _5}, empty, ErrWrongTurn, "Not black's turn!"},
+		{StartingFEN, Square{_d, _1}, Square{_d, _2}, empty, ErrOwnPiece, "Queen on d1 cannot capture own pawn on d2."},
+		{StartingFEN, Square{_g, _1}, Square{_e, _2}, empty, ErrOwnPiece, "Knight on g1 cannot capture own pawn on e2."},
+		{"4k3/8/8/8/8/4N3/4P3/4K3 w - - 0 1", Square{_e, _2}, Square{_e, _3}, empty, ErrOwnPiece,
+			"Pawn on e2 cannot capture own knight on e3."},
+		{StartingFEN, Square{_b, _1}, Square{_b, _3}, empty, ErrIllegalGeometry, "Knight on b1 cannot move to b3."},
+		{StartingFEN, Square{_g, _1}, Square{_d, _2}, empty, ErrIllegalGeometry, "Knight on g1 cannot move to d2."},
+		{StartingFEN, Square{_a, _1}, Square{_h, _2}, empty, ErrIllegalGeometry, "Rook on a1 cannot move to h2."},
+		{StartingFEN, Square{_e, _2}, Square{_f, _3}, empty, ErrIllegalGeometry, "Pawn on e2 cannot move to f3."},
+		{StartingFEN, Square{_a, _1}, Square{_a, _5}, empty, ErrPathBlocked,
+			"Rook on a1 cannot move to a5, it is blocked by pawn on a2."},
+		{"4k3/8/8/8/8/4p3/4P3/4K3 w - - 0 1", Square{_e, _2}, Square{_e, _4}, empty, ErrPathBlocked,
+			"Pawn on e2 cannot move to e4, it is blocked by pawn on e3."},
+		{"4k3/8/8/8/8/8/8/4K2R w - - 0 1", Square{_e, _1}, Square{_g, _1}, empty, ErrNoCastlingRights,
+			"Castling from e1 to g1 is not allowed, the king or rook has already moved."},
+		{"4k3/8/8/8/8/8/8/R3K1NR w KQ - 0 1", Square{_e, _1}, Square{_g, _1}, empty, ErrPathBlocked,
+			"Castling from e1 to g1 is blocked by knight on g1."},
+		{"4r1k1/8/8/8/8/8/8/R3K2R w KQ - 0 1", Square{_e, _1}, Square{_g, _1}, empty, ErrInCheck,
+			"Castling from e1 to g1 is not allowed, king on e1 is in check by rook on e8."},
+		{"3r2k1/8/8/8/8/8/8/R3K2R w KQ - 0 1", Square{_e, _1}, Square{_c, _1}, empty, ErrKingAttacked,
+			"Castling from e1 to c1 is not allowed, d1 is attacked by rook on d8."},
+		{"4k3/8/8/q7/8/8/3B4/4K3 w - - 0 1", Square{_d, _2}, Square{_e, _3}, empty, ErrPinned,
+			"Bishop on d2 is pinned against king on e1 by queen on a5."},
+		{"4k3/8/8/8/8/8/8/r3K2N w - - 0 1", Square{_h, _1}, Square{_g, _3}, empty, ErrInCheck,
+			"Move from h1 to g3 leaves king on e1 in check by rook on a1."},
+		{"4k3/7p/8/8/6n1/8/8/4K3 w - - 0 1", Square{_e, _1}, Square{_f, _2}, empty, ErrKingAttacked,
+			"King on e1 cannot move to f2, it is attacked by knight on g4."},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", Square{_b, _7}, Square{_b, _8}, empty, ErrMissingPromotion,
+			"Move from b7 to b8 needs a promotion piece, e.g. b7-b8=Q."},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", Square{_b, _7}, Square{_b, _8}, wking, ErrInvalidPromotion,
+			"Pawn cannot promote to king!"},
+		{StartingFEN, Square{_e, _2}, Square{_e, _4}, wqueen, ErrNotPromotion, "Move from e2 to e4 is not a promotion!"},
+	}
+	for _, test := range tests {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = pos.move(test.from, test.to, test.promotion)
+		if !errors.Is(err, test.want) {
+			t.Errorf("Move from %v to %v in %q should fail with %v but fails with %v", test.from, test.to, test.fen,
+				test.want, err)
+			continue
+		}
+		var moveErr *MoveError
+		if !errors.As(err, &moveErr) {
+			t.Errorf("Error %v should be a *MoveError but is %T", err, err)
+			continue
+		}
+		if moveErr.Error() != test.message {
+			t.Errorf("Error should read %q but reads %q", test.message, moveErr.Error())
+		}
+		if pos.FEN() != test.fen {
+			t.Errorf("Rejected move should leave %q unchanged but gives %q", test.fen, pos.FEN())
+		}
+	}
+}
+
+func TestPinnedErrorDetails(t *testing.T) {
+	pos, err := ParseFEN("4k3/8/8/q7/8/8/3B4/4K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var moveErr *MoveError
+	if err := pos.move(Square{_d, _2}, Square{_e, _3}, empty); !errors.As(err, &moveErr) {
+		t.Fatalf("Pinned bishop move should fail with a *MoveError but fails with %v", err)
+	}
+	if moveErr.Piece != wbishop || moveErr.King != (Square{_e, _1}) || moveErr.Cause != (Square{_a, _5}) ||
+		moveErr.CausePiece != bqueen {
+		t.Errorf("Pin should be bishop d2 against king e1 by queen a5 but is %+v", *moveErr)
+	}
+}
+
+func TestGameOverError(t *testing.T) {
+	pos, err := ParseFEN("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pos.move(Square{_h, _8}, Square{_h, _7}, empty)
+	if !errors.Is(err, ErrGameOver) || err.Error() != "Game is over: draw by stalemate." {
+		t.Errorf("Move after stalemate should fail with ErrGameOver but fails with %v", err)
+	}
+}
+
+func TestApplyInvalidPromotion(t *testing.T) {
+	pos, err := ParseFEN("4k3/1P6/8/8/8/8/8/4K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newMove(Square{_b, _7}, Square{_b, _8}, empty, 0) | Move(Rook|8)<<12
+	if err := pos.Apply(m); !errors.Is(err, ErrInvalidPromotion) {
+		t.Errorf("Move with promotion kind %d should fail with ErrInvalidPromotion but fails with %v", m.Promotion(), err)
+	}
+}
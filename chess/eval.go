@@ -0,0 +1,120 @@
+package chess
+
+var pieceValues = [...]int{King: 0, Queen: 900, Rook: 500, Bishop: 330, Knight: 320, Pawn: 100}
+
+var pieceSquareTables = [...][64]int{
+	Pawn: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	},
+	Knight: {
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	},
+	Bishop: {
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	},
+	Rook: {
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	},
+	Queen: {
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	},
+	King: {
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	},
+}
+
+var kingEndgameTable = [64]int{
+	-50, -40, -30, -20, -20, -30, -40, -50,
+	-30, -20, -10, 0, 0, -10, -20, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -30, 0, 0, 0, 0, -30, -30,
+	-50, -30, -30, -30, -30, -30, -30, -50,
+}
+
+const openingPhase = 24
+
+var phaseWeights = [...]int{King: 0, Queen: 4, Rook: 2, Bishop: 1, Knight: 1, Pawn: 0}
+
+func tableIndex(i int, player Color) int {
+	if player == White {
+		return (7-i/8)*8 + i%8
+	}
+	return i
+}
+
+func (position *Position) evaluate() int {
+	var board *board = &(position.board)
+	var score [2]int
+	var kingOpening, kingEndgame [2]int
+	phase := 0
+	for piece := wking; piece <= bpawn; piece++ {
+		kind, player := piece.Kind(), piece.Color()
+		if kind == NoKind || kind > Pawn {
+			continue
+		}
+		for pieces := board.bitboardOf(piece); pieces != 0; {
+			i := tableIndex(pieces.pop(), player)
+			phase += phaseWeights[kind]
+			if kind == King {
+				kingOpening[player.index()] += pieceSquareTables[King][i]
+				kingEndgame[player.index()] += kingEndgameTable[i]
+				continue
+			}
+			score[player.index()] += pieceValues[kind] + pieceSquareTables[kind][i]
+		}
+	}
+	phase = min(phase, openingPhase)
+	for p := range score {
+		score[p] += (kingOpening[p]*phase + kingEndgame[p]*(openingPhase-phase)) / openingPhase
+	}
+	eval := score[White.index()] - score[Black.index()]
+	if position.turn == Black {
+		return -eval
+	}
+	return eval
+}
@@ -0,0 +1,247 @@
+package chess
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const StartingFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+var fenPieces = map[rune]Piece{
+	'K': wking,
+	'Q': wqueen,
+	'R': wrook,
+	'B': wbishop,
+	'N': wknight,
+	'P': wpawn,
+	'k': bking,
+	'q': bqueen,
+	'r': brook,
+	'b': bbishop,
+	'n': bknight,
+	'p': bpawn,
+}
+
+var fenCastlings = []struct {
+	letter rune
+	right  castling
+	king   Square
+	rook   Square
+	player Color
+}{
+	{'K', whiteKingside, Square{_e, _1}, Square{_h, _1}, White},
+	{'Q', whiteQueenside, Square{_e, _1}, Square{_a, _1}, White},
+	{'k', blackKingside, Square{_e, _8}, Square{_h, _8}, Black},
+	{'q', blackQueenside, Square{_e, _8}, Square{_a, _8}, Black},
+}
+
+func (position *Position) SetFEN(fen string) error {
+	p, err := ParseFEN(fen)
+	if err != nil {
+		return err
+	}
+	*position = *p
+	return nil
+}
+
+func ParseFEN(fen string) (*Position, error) {
+	p := &Position{}
+	fields := strings.Fields(fen)
+	if len(fields) != 6 && len(fields) != 4 {
+		return nil, fmt.Errorf("FEN %q has %d fields, want 6.", fen, len(fields))
+	}
+	if err := p.board.parseFENBoard(fields[0]); err != nil {
+		return nil, err
+	}
+	switch fields[1] {
+	case "w":
+		p.turn = White
+	case "b":
+		p.turn = Black
+	default:
+		return nil, fmt.Errorf("Side to move %q is invalid, want \"w\" or \"b\".", fields[1])
+	}
+	if err := p.parseFENCastling(fields[2]); err != nil {
+		return nil, err
+	}
+	if err := p.parseFENEnPassant(fields[3]); err != nil {
+		return nil, err
+	}
+	p.halfmoveClock, p.fullmoveNumber = 0, 1
+	if len(fields) == 6 {
+		var err error
+		p.halfmoveClock, err = strconv.Atoi(fields[4])
+		if err != nil || p.halfmoveClock < 0 {
+			return nil, fmt.Errorf("Halfmove clock %q is not a non-negative number.", fields[4])
+		}
+		p.fullmoveNumber, err = strconv.Atoi(fields[5])
+		if err != nil || p.fullmoveNumber < 1 {
+			return nil, fmt.Errorf("Fullmove number %q is not a positive number.", fields[5])
+		}
+	}
+	opponentKing, _ := p.board.findKingOf(!p.turn)
+	if p.board.squareAttackedByPlayer(opponentKing, p.turn) {
+		return nil, fmt.Errorf("%v's king is in check although it is %v's turn.", !p.turn, p.turn)
+	}
+	if fen := p.FEN(); fen != StartingFEN {
+		p.setup = fen
+	}
+	p.repetitions = []uint64{p.Hash()}
+	p.updateStatus()
+	return p, nil
+}
+
+func (board *board) parseFENBoard(s string) error {
+	ranks := strings.Split(s, "/")
+	if len(ranks) != 8 {
+		return fmt.Errorf("Board %q has %d ranks, want 8.", s, len(ranks))
+	}
+	board.clear()
+	kings := map[Piece]int{}
+	for i, rank := range ranks {
+		row := _8 - i
+		file := _a
+		for _, r := range rank {
+			if '1' <= r && r <= '8' {
+				file += int(r - '0')
+				if file > _h+1 {
+					return fmt.Errorf("Rank %d %q has more than 8 squares.", row+1, rank)
+				}
+				continue
+			}
+			piece, ok := fenPieces[r]
+			if !ok {
+				return fmt.Errorf("Rank %d %q contains invalid piece %q.", row+1, rank, r)
+			}
+			if file > _h {
+				return fmt.Errorf("Rank %d %q has more than 8 squares.", row+1, rank)
+			}
+			if piece.Kind() == Pawn && (row == _1 || row == _8) {
+				return fmt.Errorf("Rank %d %q contains a pawn.", row+1, rank)
+			}
+			if piece.Kind() == King {
+				kings[piece]++
+			}
+			board.set(Square{file, row}, piece)
+			file++
+		}
+		if file <= _h {
+			return fmt.Errorf("Rank %d %q has %d squares, want 8.", row+1, rank, file)
+		}
+	}
+	for _, king := range []Piece{wking, bking} {
+		if kings[king] != 1 {
+			return fmt.Errorf("Board %q has %d %v kings, want 1.", s, kings[king], king.Color())
+		}
+	}
+	return nil
+}
+
+func (position *Position) parseFENCastling(s string) error {
+	position.castling = 0
+	if s == "-" {
+		return nil
+	}
+	var board *board = &(position.board)
+	for _, r := range s {
+		found := false
+		for _, c := range fenCastlings {
+			if c.letter != r {
+				continue
+			}
+			found = true
+			if position.castling&c.right != 0 {
+				return fmt.Errorf("Castling rights %q contain %q twice.", s, r)
+			}
+			if board.at(c.king) != colored(wking, c.player) ||
+				board.at(c.rook) != colored(wrook, c.player) {
+				return fmt.Errorf("Castling right %q needs king on %v and rook on %v.", r, c.king, c.rook)
+			}
+			position.castling |= c.right
+		}
+		if !found {
+			return fmt.Errorf("Castling rights %q contain invalid character %q.", s, r)
+		}
+	}
+	return nil
+}
+
+func (position *Position) parseFENEnPassant(s string) error {
+	position.enPassant = nil
+	if s == "-" {
+		return nil
+	}
+	sq, err := ParseSquare(s)
+	if err != nil {
+		return fmt.Errorf("En passant square %q is invalid.", s)
+	}
+	row, pawnRow, pawn := _6, _5, bpawn
+	if position.turn == Black {
+		row, pawnRow, pawn = _3, _4, wpawn
+	}
+	if sq.row != row {
+		return fmt.Errorf("En passant square %q is not on rank %d.", s, row+1)
+	}
+	if position.board.at(Square{sq.file, pawnRow}) != pawn || position.board.at(sq) != empty {
+		return fmt.Errorf("En passant square %q has no %v on %v behind it.", s, pawn, Square{sq.file, pawnRow})
+	}
+	position.enPassant = &sq
+	return nil
+}
+
+func (position *Position) FEN() string {
+	var b strings.Builder
+	var board *board = &(position.board)
+	for row := _8; row >= _1; row-- {
+		empties := 0
+		for file := _a; file <= _h; file++ {
+			if board.at(Square{file, row}) == empty {
+				empties++
+				continue
+			}
+			if empties > 0 {
+				b.WriteString(strconv.Itoa(empties))
+				empties = 0
+			}
+			b.WriteRune(fenLetter(board.at(Square{file, row})))
+		}
+		if empties > 0 {
+			b.WriteString(strconv.Itoa(empties))
+		}
+		if row > _1 {
+			b.WriteRune('/')
+		}
+	}
+	if position.turn == White {
+		b.WriteString(" w ")
+	} else {
+		b.WriteString(" b ")
+	}
+	if position.castling == 0 {
+		b.WriteRune('-')
+	}
+	for _, c := range fenCastlings {
+		if position.castling&c.right != 0 {
+			b.WriteRune(c.letter)
+		}
+	}
+	if position.enPassant == nil {
+		b.WriteString(" -")
+	} else {
+		fmt.Fprintf(&b, " %v", *position.enPassant)
+	}
+	fmt.Fprintf(&b, " %d %d", position.halfmoveClock, position.fullmoveNumber)
+	return b.String()
+}
+
+func fenLetter(piece Piece) rune {
+	return ASCIIPieces.glyph(piece)
+}
+
+func ParseSquare(s string) (Square, error) {
+	if len(s) != 2 || s[0] < 'a' || 'h' < s[0] || s[1] < '1' || '8' < s[1] {
+		return Square{}, fmt.Errorf("Square %q does not match format", s)
+	}
+	return Square{int(s[0] - fileUnicodeOffset), int(s[1] - rowUnicodeOffset)}, nil
+}
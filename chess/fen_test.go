@@ -0,0 +1,132 @@
+package chess
+
+import (
+	"testing"
+)
+
+func TestParseFENStartingPos(t *testing.T) {
+	got, err := ParseFEN(StartingFEN)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want Position
+	want.startingPos()
+	if got.board != want.board || got.turn != want.turn || got.castling != want.castling ||
+		got.enPassant != nil || got.halfmoveClock != 0 || got.fullmoveNumber != 1 {
+		t.Errorf("Parsed FEN %q differs from the starting position.\n%v", StartingFEN, got.board.formatb())
+	}
+}
+
+func TestParseFENFields(t *testing.T) {
+	fen := "r3k2r/8/8/3pP3/8/8/8/R3K2R w Kq d6 3 42"
+	pos, err := ParseFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos.board.at(Square{_a, _8}) != brook || pos.board.at(Square{_e, _1}) != wking || pos.board.at(Square{_d, _5}) != bpawn {
+		t.Errorf("Board of FEN %q is parsed incorrectly.\n%v", fen, pos.board.formatb())
+	}
+	if pos.turn != White || pos.castling != whiteKingside|blackQueenside {
+		t.Errorf("FEN %q should give turn %v and castling %b but gives %v and %b.", fen, White,
+			whiteKingside|blackQueenside, pos.turn, pos.castling)
+	}
+	if pos.enPassant == nil || *pos.enPassant != (Square{_d, _6}) {
+		t.Errorf("FEN %q should give en passant square %v but gives %v.", fen, Square{_d, _6}, pos.enPassant)
+	}
+	if pos.halfmoveClock != 3 || pos.fullmoveNumber != 42 {
+		t.Errorf("FEN %q should give clocks 3 and 42 but gives %d and %d.", fen, pos.halfmoveClock,
+			pos.fullmoveNumber)
+	}
+}
+
+func TestParseFENStatus(t *testing.T) {
+	fen := "7k/5Q2/6K1/8/8/8/8/8 b - - 0 1"
+	pos, err := ParseFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos.status != Draw || pos.reason != Stalemate {
+		t.Errorf("FEN %q should be %v by %v but is %v by %v.", fen, Draw, Stalemate, pos.status, pos.reason)
+	}
+}
+
+func TestParseFENError(t *testing.T) {
+	fens := []string{
+		"",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -  0",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNRR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBN w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/ppppxppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNP w KQkq - 0 1",
+		"rnbqqbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQ - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkx - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KKkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/1NBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e3 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq e3 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq z9 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - -1 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0",
+		"4k3/8/8/8/8/8/8/4R2K w - - 0 1",
+		"4k2R/8/8/8/8/8/8/4K3 w - - 0 1",
+	}
+	for _, fen := range fens {
+		if _, err := ParseFEN(fen); err == nil {
+			t.Errorf("Parsing FEN %q should error but does not.", fen)
+		}
+	}
+}
+
+func TestSetFENKeepsPositionOnError(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	want := pos.FEN()
+	if err := pos.SetFEN("8/8/8/8/8/8/8/8 w - - 0 1"); err == nil {
+		t.Fatal("Setting a FEN without kings should error but does not.")
+	}
+	if got := pos.FEN(); got != want {
+		t.Errorf("Failed FEN import should not change the position. Want %q but got %q.", want, got)
+	}
+}
+
+func TestFENRoundTrip(t *testing.T) {
+	fens := []string{
+		StartingFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"rnbqkbnr/pp1ppppp/8/2pP4/8/8/PPP1PPPP/RNBQKBNR w KQkq c6 0 3",
+		"4k3/8/8/8/8/8/8/4K3 b - - 12 80",
+	}
+	for _, fen := range fens {
+		pos, err := ParseFEN(fen)
+		if err != nil {
+			t.Errorf("Parsing FEN %q should not error but does: %v", fen, err)
+			continue
+		}
+		if got := pos.FEN(); got != fen {
+			t.Errorf("FEN round trip is wrong. Want %q but got %q.", fen, got)
+		}
+	}
+}
+
+func TestFENAfterMoves(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	moves := [][2]Square{
+		{{_e, _2}, {_e, _4}},
+		{{_c, _7}, {_c, _5}},
+		{{_g, _1}, {_f, _3}},
+	}
+	for _, m := range moves {
+		if err := pos.move(m[0], m[1], empty); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2"
+	if got := pos.FEN(); got != want {
+		t.Errorf("FEN is wrong. Want %q but got %q.", want, got)
+	}
+}
@@ -0,0 +1,129 @@
+package chess
+
+import (
+	"errors"
+	"testing"
+)
+
+func addFuzzPositions(f *testing.F, add func(fen string)) {
+	add(StartingFEN)
+	for _, test := range perftPositions {
+		add(test.fen)
+	}
+	add("4k3/1P6/8/8/8/8/8/4K3 w - - 0 1")
+	add("4k3/8/8/q7/8/8/3B4/4K3 w - - 0 1")
+	add("8/8/8/KPp4r/8/8/8/7k w - c6 0 2")
+}
+
+func legal(position *Position, m Move) bool {
+	for _, l := range position.LegalMoves() {
+		if l.From() == m.From() && l.To() == m.To() && l.Promotion() == m.Promotion() {
+			return true
+		}
+	}
+	return false
+}
+
+func FuzzApply(f *testing.F) {
+	addFuzzPositions(f, func(fen string) {
+		f.Add(fen, uint32(newMove(Square{_e, _2}, Square{_e, _4}, empty, 0)))
+		f.Add(fen, uint32(newMove(Square{_b, _7}, Square{_b, _8}, wqueen, 0)))
+		f.Add(fen, uint32(0xffffffff))
+	})
+	f.Fuzz(func(t *testing.T, fen string, m uint32) {
+		position, err := ParseFEN(fen)
+		if err != nil {
+			return
+		}
+		before := position.FEN()
+		isLegal := legal(position, Move(m))
+		err = position.Apply(Move(m))
+		if isLegal != (err == nil) {
+			t.Fatalf("Move %v in %q should be legal %v but Apply returns %v", Move(m), fen, isLegal, err)
+		}
+		if err != nil && position.FEN() != before {
+			t.Errorf("Rejected move %v should leave %q unchanged but gives %q", Move(m), before, position.FEN())
+		}
+	})
+}
+
+func FuzzMoveSquares(f *testing.F) {
+	addFuzzPositions(f, func(fen string) {
+		f.Add(fen, _e, _2, _e, _4, uint8(empty))
+		f.Add(fen, _e, _2, _e, 8, uint8(empty))
+		f.Add(fen, -1, _2, _e, -9, uint8(wqueen))
+		f.Add(fen, _b, _7, _b, _8, uint8(255))
+	})
+	f.Fuzz(func(t *testing.T, fen string, fromFile, fromRow, toFile, toRow int, promotion uint8) {
+		position, err := ParseFEN(fen)
+		if err != nil {
+			return
+		}
+		from, to := Square{fromFile, fromRow}, Square{toFile, toRow}
+		err = position.move(from, to, Piece(promotion))
+		var moveErr *MoveError
+		if err != nil && !errors.As(err, &moveErr) && !errors.Is(err, ErrGameOver) {
+			t.Errorf("Move from %v to %v in %q should fail with a *MoveError but fails with %v", from, to, fen, err)
+		}
+		if err == nil && (!withinBounds(from) || !withinBounds(to)) {
+			t.Errorf("Move from %v to %v in %q should be out of bounds but is applied", from, to, fen)
+		}
+		position.At(from)
+		position.At(to)
+	})
+}
+
+func FuzzParseMove(f *testing.F) {
+	addFuzzPositions(f, func(fen string) {
+		for _, input := range []string{"e4", "e2-e4", "e2e4", "O-O", "Nxf3+", "b8=Q", "b7b8k", "Bd3", ""} {
+			f.Add(fen, input)
+		}
+	})
+	f.Fuzz(func(t *testing.T, fen, input string) {
+		position, err := ParseFEN(fen)
+		if err != nil {
+			return
+		}
+		m, err := position.ParseMove(input)
+		if err != nil {
+			return
+		}
+		isLegal := legal(position, m)
+		if err := position.Apply(m); isLegal != (err == nil) {
+			t.Errorf("Move %q in %q should be legal %v but Apply returns %v", input, fen, isLegal, err)
+		}
+	})
+}
+
+func FuzzRandomGame(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{12, 7, 3, 200, 41, 99, 0, 17, 255, 128, 64, 32, 16, 8, 4, 2, 1})
+	f.Add([]byte("a long string of pseudo random move choices for a random game"))
+	f.Fuzz(func(t *testing.T, choices []byte) {
+		position := NewPosition()
+		for _, choice := range choices {
+			moves := position.LegalMoves()
+			if len(moves) != position.Perft(1) && position.Status() == Ongoing {
+				t.Fatalf("Legal moves of %q should match Perft(1) %d but are %d", position.FEN(),
+					position.Perft(1), len(moves))
+			}
+			if len(moves) == 0 {
+				if position.Status() == Ongoing {
+					t.Fatalf("Position %q without legal moves should be over", position.FEN())
+				}
+				break
+			}
+			m := moves[int(choice)%len(moves)]
+			if err := position.Apply(m); err != nil {
+				t.Fatalf("Legal move %v in %q should apply but fails: %v", m, position.FEN(), err)
+			}
+			copied, err := ParseFEN(position.FEN())
+			if err != nil {
+				t.Fatalf("FEN %q of a reachable position should parse but fails: %v", position.FEN(), err)
+			}
+			if copied.FEN() != position.FEN() {
+				t.Fatalf("FEN %q should round trip but gives %q", position.FEN(), copied.FEN())
+			}
+		}
+	})
+}
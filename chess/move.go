@@ -0,0 +1,300 @@
+package chess
+
+import (
+	"fmt"
+)
+
+type Move uint32
+
+const (
+	captureFlag Move = 1 << (16 + iota)
+	enPassantFlag
+	castlingFlag
+	doublePushFlag
+)
+
+const maxMoves = 256
+
+type moveList struct {
+	moves [maxMoves]Move
+	size  int
+}
+
+type undo struct {
+	move     Move
+	captured Piece
+	before   irreversible
+}
+
+var allSquares [64]Square
+
+func init() {
+	for i := range allSquares {
+		allSquares[i] = squareAt(i)
+	}
+}
+
+func newMove(from, to Square, promotion Piece, flags Move) Move {
+	m := Move(from.index()) | Move(to.index())<<6 | flags
+	return m | Move(promotion.Kind())<<12
+}
+
+func (m Move) From() Square {
+	return squareAt(int(m & 0x3f))
+}
+
+func (m Move) To() Square {
+	return squareAt(int(m >> 6 & 0x3f))
+}
+
+func (m Move) promotion(player Color) Piece {
+	return NewPiece(PieceKind(m>>12&0xf), player)
+}
+
+func (m Move) Promotion() PieceKind {
+	return PieceKind(m >> 12 & 0xf)
+}
+
+func (m Move) is(flag Move) bool {
+	return m&flag != 0
+}
+
+func (m Move) UCI() string {
+	if promotion := m.promotion(Black); promotion != empty {
+		return m.From().String() + m.To().String() + string(fenLetter(promotion))
+	}
+	return m.From().String() + m.To().String()
+}
+
+func (m Move) String() string {
+	return m.UCI()
+}
+
+func (m move) packed() Move {
+	var flags Move
+	if m.captured != empty {
+		flags |= captureFlag
+	}
+	if m.enPassant {
+		flags |= enPassantFlag
+	}
+	if m.piece.Kind() == King && abs(m.to.file-m.from.file) == 2 {
+		flags |= castlingFlag
+	}
+	if m.piece.Kind() == Pawn && abs(m.to.row-m.from.row) == 2 {
+		flags |= doublePushFlag
+	}
+	return newMove(m.from, m.to, m.promotion, flags)
+}
+
+func (position *Position) describe(m Move) move {
+	var board *board = &(position.board)
+	from, to := m.From(), m.To()
+	d := move{from: from, to: to, piece: board.at(from), captured: board.at(to), promotion: m.promotion(position.turn),
+		enPassant: m.is(enPassantFlag)}
+	if d.enPassant {
+		d.captured = board.at(Square{to.file, from.row})
+	}
+	return d
+}
+
+func (list *moveList) add(m Move) {
+	list.moves[list.size] = m
+	list.size++
+}
+
+func (list *moveList) slice() []Move {
+	return list.moves[:list.size]
+}
+
+func (position *Position) makeMove(m Move) {
+	var board *board = &(position.board)
+	from, to := m.From(), m.To()
+	piece := board.at(from)
+	u := undo{move: m, captured: board.at(to),
+		before: irreversible{position.castling, position.enPassant, position.halfmoveClock}}
+	if m.is(enPassantFlag) {
+		u.captured = board.at(Square{to.file, from.row})
+		board.set(Square{to.file, from.row}, empty)
+	}
+	board.set(from, empty)
+	board.set(to, piece)
+	if promotion := m.promotion(position.turn); promotion != empty {
+		board.set(to, promotion)
+	}
+	if m.is(castlingFlag) {
+		rookFrom, rookTo := castlingRookSquares(to)
+		board.set(rookTo, board.at(rookFrom))
+		board.set(rookFrom, empty)
+	}
+	position.updateCastling(from, to, piece)
+	position.enPassant = nil
+	if m.is(doublePushFlag) {
+		position.enPassant = &allSquares[(from.index()+to.index())/2]
+	}
+	if piece.Kind() == Pawn || u.captured != empty {
+		position.halfmoveClock = 0
+	} else {
+		position.halfmoveClock++
+	}
+	if position.turn == Black {
+		position.fullmoveNumber++
+	}
+	position.turn = !position.turn
+	position.undos = append(position.undos, u)
+	position.repetitions = append(position.repetitions, position.Hash())
+}
+
+func (position *Position) unmakeMove() undo {
+	var board *board = &(position.board)
+	u := position.undos[len(position.undos)-1]
+	position.turn = !position.turn
+	if position.turn == Black {
+		position.fullmoveNumber--
+	}
+	from, to := u.move.From(), u.move.To()
+	piece := board.at(to)
+	if u.move.promotion(position.turn) != empty {
+		piece = colored(wpawn, position.turn)
+	}
+	board.set(from, piece)
+	board.set(to, u.captured)
+	if u.move.is(enPassantFlag) {
+		board.set(to, empty)
+		board.set(Square{to.file, from.row}, u.captured)
+	}
+	if u.move.is(castlingFlag) {
+		rookFrom, rookTo := castlingRookSquares(to)
+		board.set(rookFrom, board.at(rookTo))
+		board.set(rookTo, empty)
+	}
+	position.castling = u.before.castling
+	position.enPassant = u.before.enPassant
+	position.halfmoveClock = u.before.halfmoveClock
+	position.undos = position.undos[:len(position.undos)-1]
+	position.repetitions = position.repetitions[:len(position.repetitions)-1]
+	return u
+}
+
+func (position *Position) generateMoves(list *moveList) {
+	var board *board = &(position.board)
+	list.size = 0
+	safe := position.safePieces()
+	for piece := colored(wking, position.turn); piece <= colored(wpawn, position.turn); piece++ {
+		for pieces := board.bitboardOf(piece); pieces != 0; {
+			from := squareAt(pieces.pop())
+			for targets := position.targets(from, piece); targets != 0; {
+				to := squareAt(targets.pop())
+				var flags Move
+				if board.at(to) != empty {
+					flags |= captureFlag
+				}
+				if piece.Kind() == Pawn && abs(to.row-from.row) == 2 {
+					flags |= doublePushFlag
+				}
+				position.addMove(list, safe, from, to, piece, flags)
+			}
+		}
+	}
+	position.generateSpecialMoves(list)
+}
+
+func (position *Position) generateSpecialMoves(list *moveList) {
+	var board *board = &(position.board)
+	player := position.turn
+	if ep := position.enPassant; ep != nil {
+		pawn := colored(wpawn, player)
+		for pawns := pawnAttacks[(!player).index()][ep.index()] & board.bitboardOf(pawn); pawns != 0; {
+			from := squareAt(pawns.pop())
+			if position.validateEnPassant(from, *ep) {
+				position.addMove(list, 0, from, *ep, pawn, captureFlag|enPassantFlag)
+			}
+		}
+	}
+	if king, err := board.findKingOf(player); err == nil {
+		for _, fileDiff := range []int{2, -2} {
+			to := Square{king.file + fileDiff, king.row}
+			if withinBounds(to) && position.validateCastling(king, to) {
+				list.add(newMove(king, to, empty, castlingFlag))
+			}
+		}
+	}
+}
+
+func (position *Position) addMove(list *moveList, safe bitboard, from, to Square, piece Piece, flags Move) {
+	m := newMove(from, to, empty, flags)
+	if safe&from.bit() == 0 {
+		if isCheckedAfter, _ := position.board.kingIsCheckedAfterMove(m); isCheckedAfter {
+			return
+		}
+	}
+	if !isPromotion(piece, to) {
+		list.add(m)
+		return
+	}
+	for _, promotion := range promotionPieces(White) {
+		list.add(newMove(from, to, promotion, flags))
+	}
+}
+
+func (position *Position) countValidMoves() (count int) {
+	var board *board = &(position.board)
+	safe := position.safePieces()
+	for piece := colored(wking, position.turn); piece <= colored(wpawn, position.turn); piece++ {
+		for pieces := board.bitboardOf(piece); pieces != 0; {
+			from := squareAt(pieces.pop())
+			targets := position.targets(from, piece)
+			if safe&from.bit() != 0 {
+				count += targets.count()
+				if piece.Kind() == Pawn {
+					count += 3 * (targets & (rank1 | rank8)).count()
+				}
+				continue
+			}
+			for targets != 0 {
+				to := squareAt(targets.pop())
+				if isCheckedAfter, _ := board.kingIsCheckedAfterMove(newMove(from, to, empty, 0)); isCheckedAfter {
+					continue
+				}
+				if isPromotion(piece, to) {
+					count += 4
+				} else {
+					count++
+				}
+			}
+		}
+	}
+	var special moveList
+	position.generateSpecialMoves(&special)
+	return count + special.size
+}
+
+func (position *Position) safePieces() bitboard {
+	var board *board = &(position.board)
+	if position.InCheck() {
+		return 0
+	}
+	return board.occupied[position.turn.index()] &^ board.pinned(position.turn) &^
+		board.bitboardOf(colored(wking, position.turn))
+}
+
+func (position *Position) targets(from Square, piece Piece) bitboard {
+	var board *board = &(position.board)
+	targets := board.attacks(from, board.all()) &^ board.occupied[position.turn.index()]
+	if piece.Kind() != Pawn {
+		return targets
+	}
+	if from.row == _1 || from.row == _8 {
+		panic(fmt.Sprintf("Impossible pawn position: %v", from))
+	}
+	return targets&board.all() | board.pawnPushes(from)
+}
+
+func (position *Position) validMoves() (moves []move) {
+	var list moveList
+	position.generateMoves(&list)
+	for _, m := range list.slice() {
+		moves = append(moves, position.describe(m))
+	}
+	return
+}
@@ -0,0 +1,92 @@
+package chess
+
+import (
+	"testing"
+)
+
+func TestNewMove(t *testing.T) {
+	tests := []struct {
+		from, to  Square
+		promotion Piece
+		flags     Move
+		player    Color
+		uci       string
+	}{
+		{Square{_e, _2}, Square{_e, _4}, empty, doublePushFlag, White, "e2e4"},
+		{Square{_b, _7}, Square{_a, _8}, wknight, captureFlag, White, "b7a8n"},
+		{Square{_g, _2}, Square{_g, _1}, bqueen, 0, Black, "g2g1q"},
+		{Square{_e, _8}, Square{_c, _8}, empty, castlingFlag, Black, "e8c8"},
+	}
+	for _, test := range tests {
+		m := newMove(test.from, test.to, test.promotion, test.flags)
+		if m.From() != test.from || m.To() != test.to || m.promotion(test.player) != colored(test.promotion, test.player) {
+			t.Errorf("Move %v should unpack to %v, %v and %v but unpacks to %v, %v and %v.", m, test.from, test.to,
+				test.promotion, m.From(), m.To(), m.promotion(test.player))
+		}
+		for _, flag := range []Move{captureFlag, enPassantFlag, castlingFlag, doublePushFlag} {
+			if m.is(flag) != (test.flags&flag != 0) {
+				t.Errorf("Move %v should have flags %x but has %x.", m, test.flags, m)
+			}
+		}
+		if m.UCI() != test.uci {
+			t.Errorf("UCI of %v should be %q but is %q.", m, test.uci, m.UCI())
+		}
+	}
+}
+
+func TestMakeUnmakeMove(t *testing.T) {
+	for _, test := range perftPositions {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before, board := pos.FEN(), pos.board
+		var list moveList
+		pos.generateMoves(&list)
+		for _, m := range list.slice() {
+			pos.makeMove(m)
+			pos.unmakeMove()
+			if got := pos.FEN(); got != before || pos.board != board {
+				t.Errorf("Making and unmaking %v in %s should restore %q but gives %q.", m, test.name, before, got)
+			}
+		}
+	}
+}
+
+func TestMakeMoveMatchesMove(t *testing.T) {
+	for _, test := range perftPositions {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range pos.validMoves() {
+			want := pos.Clone()
+			if err := want.move(m.from, m.to, m.promotion); err != nil {
+				t.Fatalf("Move %v in %s should be legal but errors: %v", m.packed().UCI(), test.name, err)
+			}
+			pos.makeMove(m.packed())
+			if got := pos.FEN(); got != want.FEN() {
+				t.Errorf("Making %v in %s should give %q but gives %q.", m.packed().UCI(), test.name, want.FEN(), got)
+			}
+			pos.unmakeMove()
+		}
+	}
+}
+
+func TestGenerateMovesAllocations(t *testing.T) {
+	pos, err := ParseFEN(perftPositions[1].fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list moveList
+	allocs := testing.AllocsPerRun(100, func() {
+		pos.generateMoves(&list)
+		for _, m := range list.slice() {
+			pos.makeMove(m)
+			pos.unmakeMove()
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Generating, making and unmaking moves should not allocate but allocates %v times.", allocs)
+	}
+}
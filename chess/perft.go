@@ -0,0 +1,34 @@
+package chess
+
+func (position *Position) Perft(depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	if depth == 1 {
+		return position.countValidMoves()
+	}
+	var list moveList
+	position.generateMoves(&list)
+	nodes := 0
+	for _, m := range list.slice() {
+		position.makeMove(m)
+		nodes += position.Perft(depth - 1)
+		position.unmakeMove()
+	}
+	return nodes
+}
+
+func (position *Position) Divide(depth int) map[string]int {
+	nodes := make(map[string]int)
+	if depth < 1 {
+		return nodes
+	}
+	var list moveList
+	position.generateMoves(&list)
+	for _, m := range list.slice() {
+		position.makeMove(m)
+		nodes[m.UCI()] = position.Perft(depth - 1)
+		position.unmakeMove()
+	}
+	return nodes
+}
@@ -0,0 +1,59 @@
+package chess
+
+import (
+	"testing"
+)
+
+var perftPositions = []struct {
+	name  string
+	fen   string
+	nodes []int
+}{
+	{"initial", StartingFEN, []int{20, 400, 8902, 197281}},
+	{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []int{48, 2039, 97862, 4085603}},
+	{"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []int{14, 191, 2812, 43238, 674624}},
+	{"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []int{6, 264, 9467, 422333}},
+	{"position 4 mirrored", "r2q1rk1/pP1p2pp/Q4n2/bbp1p3/Np6/1B3NBn/pPPP1PPP/R3K2R b KQ - 0 1", []int{6, 264, 9467, 422333}},
+	{"position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []int{44, 1486, 62379, 2103487}},
+	{"position 6", "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		[]int{46, 2079, 89890, 3894594}},
+}
+
+func TestPerft(t *testing.T) {
+	for _, test := range perftPositions {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for depth, want := range test.nodes {
+			depth++
+			if testing.Short() && want > 10000 {
+				break
+			}
+			if got := pos.Perft(depth); got != want {
+				t.Errorf("Perft(%d) of %s should be %d but is %d.", depth, test.name, want, got)
+			}
+		}
+		if got := pos.FEN(); got != test.fen {
+			t.Errorf("Perft of %s should leave the position unchanged but gives %q.", test.name, got)
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	pos, err := ParseFEN(perftPositions[1].fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := pos.Divide(2)
+	if len(got) != 48 || got["e1g1"] != 43 || got["d5e6"] != 46 || got["e2a6"] != 36 {
+		t.Errorf("Divide(2) of kiwipete is wrong: %v", got)
+	}
+	total := 0
+	for _, nodes := range got {
+		total += nodes
+	}
+	if total != 2039 {
+		t.Errorf("Divide(2) of kiwipete should sum to %d but sums to %d.", 2039, total)
+	}
+}
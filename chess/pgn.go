@@ -0,0 +1,285 @@
+package chess
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const pgnLineLength = 80
+
+var sevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+var pgnEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func (position *Position) PGN(tags map[string]string) string {
+	values := map[string]string{
+		"Event": "?",
+		"Site":  "?",
+		"Date":  "????.??.??",
+		"Round": "?",
+		"White": "?",
+		"Black": "?",
+	}
+	maps.Copy(values, position.tags)
+	maps.Copy(values, tags)
+	values["Result"] = position.resultToken()
+	names := slices.Clone(sevenTagRoster)
+	if position.setup != "" {
+		values["SetUp"] = "1"
+		values["FEN"] = position.setup
+		names = append(names, "SetUp", "FEN")
+	}
+	var others []string
+	for name := range values {
+		if !slices.Contains(names, name) {
+			others = append(others, name)
+		}
+	}
+	slices.Sort(others)
+	var b strings.Builder
+	for _, name := range append(names, others...) {
+		fmt.Fprintf(&b, "[%s \"%s\"]\n", name, pgnEscaper.Replace(values[name]))
+	}
+	b.WriteString("\n")
+	b.WriteString(wrapPGN(append(position.movetext(), position.resultToken())))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func (position *Position) movetext() (tokens []string) {
+	number, turn := 1, White
+	if position.setup != "" {
+		if start, err := ParseFEN(position.setup); err == nil {
+			number, turn = start.fullmoveNumber, start.turn
+		}
+	}
+	for i, m := range position.history {
+		if turn == White {
+			tokens = append(tokens, fmt.Sprintf("%d.", number))
+		} else if i == 0 {
+			tokens = append(tokens, fmt.Sprintf("%d...", number))
+		}
+		tokens = append(tokens, m.san)
+		if turn == Black {
+			number++
+		}
+		turn = !turn
+	}
+	return tokens
+}
+
+func wrapPGN(tokens []string) string {
+	var b strings.Builder
+	lineLength := 0
+	for _, token := range tokens {
+		if lineLength > 0 && lineLength+1+len(token) >= pgnLineLength {
+			b.WriteString("\n")
+			lineLength = 0
+		} else if lineLength > 0 {
+			b.WriteString(" ")
+			lineLength++
+		}
+		b.WriteString(token)
+		lineLength += len(token)
+	}
+	return b.String()
+}
+
+func (position *Position) resultToken() string {
+	switch position.status {
+	case WhiteWins:
+		return "1-0"
+	case BlackWins:
+		return "0-1"
+	case Draw:
+		return "1/2-1/2"
+	}
+	if position.result != "" {
+		return position.result
+	}
+	return "*"
+}
+
+func (position *Position) Tags() map[string]string {
+	tags := map[string]string{}
+	maps.Copy(tags, position.tags)
+	return tags
+}
+
+type pgnGame struct {
+	tags   map[string]string
+	moves  []string
+	result string
+}
+
+var pgnMoveNumber = regexp.MustCompile(`^[0-9]+\.+`)
+
+func readPGN(r io.Reader) ([]pgnGame, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	s := string(data)
+	var games []pgnGame
+	game := pgnGame{tags: map[string]string{}}
+	depth, line := 0, 1
+	finish := func() {
+		games = append(games, game)
+		game = pgnGame{tags: map[string]string{}}
+		depth = 0
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == ';' || (c == '%' && (i == 0 || s[i-1] == '\n')):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("Game %d, line %d: Comment is not closed.", len(games)+1, line)
+			}
+			line += strings.Count(s[i:i+end], "\n")
+			i += end + 1
+		case c == '[':
+			if len(game.moves) > 0 || depth > 0 {
+				finish()
+			}
+			name, value, n, err := parsePGNTag(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("Game %d, line %d: %v", len(games)+1, line, err)
+			}
+			game.tags[name] = value
+			i += n
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			if depth == 0 {
+				return nil, fmt.Errorf("Game %d, line %d: Variation is closed but not opened.", len(games)+1, line)
+			}
+			depth--
+			i++
+		case c == '$':
+			i++
+			for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+				i++
+			}
+		default:
+			end := i
+			for end < len(s) && !strings.ContainsRune(" \t\r\n[](){};$", rune(s[end])) {
+				end++
+			}
+			token := pgnMoveNumber.ReplaceAllString(s[i:end], "")
+			i = end
+			if token == "" || depth > 0 {
+				continue
+			}
+			switch token {
+			case "1-0", "0-1", "1/2-1/2", "*":
+				game.result = token
+				finish()
+				continue
+			}
+			game.moves = append(game.moves, strings.TrimRight(token, "!?"))
+		}
+	}
+	if depth > 0 {
+		return nil, fmt.Errorf("Game %d, line %d: Variation is not closed.", len(games)+1, line)
+	}
+	if len(game.tags) > 0 || len(game.moves) > 0 {
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+func parsePGNTag(s string) (name, value string, n int, err error) {
+	i := 1
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	start := i
+	for i < len(s) && (unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i])) || s[i] == '_') {
+		i++
+	}
+	name = s[start:i]
+	for i < len(s) && s[i] == ' ' {
+		i++
+	}
+	if name == "" || i >= len(s) || s[i] != '"' {
+		return "", "", 0, fmt.Errorf("Tag is malformed.")
+	}
+	var b strings.Builder
+	for i++; i < len(s) && s[i] != '"'; i++ {
+		if s[i] == '\n' {
+			return "", "", 0, fmt.Errorf("Value of tag %s is not closed.", name)
+		}
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	for i++; i < len(s) && s[i] == ' '; i++ {
+	}
+	if i >= len(s) || s[i] != ']' {
+		return "", "", 0, fmt.Errorf("Tag %s is not closed.", name)
+	}
+	return name, b.String(), i + 1, nil
+}
+
+func (position *Position) replay(game pgnGame) (ply int, err error) {
+	if fen, ok := game.tags["FEN"]; ok {
+		if err := position.SetFEN(fen); err != nil {
+			return 0, err
+		}
+	} else {
+		position.startingPos()
+	}
+	for i, san := range game.moves {
+		m, err := position.parseSAN(san)
+		if err == nil {
+			err = position.move(m.from, m.to, m.promotion)
+		}
+		if err != nil {
+			return i + 1, err
+		}
+	}
+	position.tags = maps.Clone(game.tags)
+	for _, name := range []string{"SetUp", "FEN", "Result"} {
+		delete(position.tags, name)
+	}
+	if position.status == Ongoing && game.result != "*" {
+		position.result = game.result
+	}
+	return len(game.moves), nil
+}
+
+func LoadPGN(r io.Reader) ([]*Position, error) {
+	games, err := readPGN(r)
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]*Position, len(games))
+	for i, game := range games {
+		positions[i] = &Position{}
+		ply, err := positions[i].replay(game)
+		if err != nil && ply == 0 {
+			return nil, fmt.Errorf("Game %d: %v", i+1, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Game %d, ply %d: %v", i+1, ply, err)
+		}
+	}
+	return positions, nil
+}
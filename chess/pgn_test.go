@@ -0,0 +1,207 @@
+package chess
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPGN(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	moves := [][2]Square{
+		{{_f, _2}, {_f, _3}},
+		{{_e, _7}, {_e, _5}},
+		{{_g, _2}, {_g, _4}},
+		{{_d, _8}, {_h, _4}},
+	}
+	for _, m := range moves {
+		if err := pos.move(m[0], m[1], empty); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := pos.PGN(map[string]string{"White": "Fool", "Black": `Anna "The Quick"`, "Annotator": "Test"})
+	want := `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Fool"]
+[Black "Anna \"The Quick\""]
+[Result "0-1"]
+[Annotator "Test"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+`
+	if got != want {
+		t.Errorf("PGN is wrong. Want\n%s\nbut got\n%s", want, got)
+	}
+}
+
+func TestPGNSetUp(t *testing.T) {
+	fen := "4k3/8/8/8/8/8/4p3/4K3 b - - 0 37"
+	pos, err := ParseFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pos.move(Square{_e, _8}, Square{_d, _7}, empty); err != nil {
+		t.Fatal(err)
+	}
+	got := pos.PGN(nil)
+	if !strings.Contains(got, "[SetUp \"1\"]\n[FEN \""+fen+"\"]\n") {
+		t.Errorf("PGN should contain SetUp and FEN tags but does not:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "\n\n37... Kd7 *\n") {
+		t.Errorf("PGN movetext should start with black's move number but does not:\n%s", got)
+	}
+}
+
+func TestWrapPGN(t *testing.T) {
+	var tokens []string
+	for i := 0; i < 60; i++ {
+		tokens = append(tokens, "1.", "Nf3", "Nf6")
+	}
+	got := wrapPGN(tokens)
+	for _, line := range strings.Split(got, "\n") {
+		if len(line) >= pgnLineLength || strings.HasPrefix(line, " ") || strings.HasSuffix(line, " ") {
+			t.Errorf("Line %q is not wrapped correctly.", line)
+		}
+	}
+	if strings.Join(strings.Fields(got), " ") != strings.Join(tokens, " ") {
+		t.Errorf("Wrapping changes the tokens:\n%s", got)
+	}
+}
+
+func TestWrapPGNExactLength(t *testing.T) {
+	tokens := []string{strings.Repeat("a", 39), strings.Repeat("b", 40)}
+	if got := wrapPGN(tokens); !strings.Contains(got, "\n") {
+		t.Errorf("Line of %d characters should be wrapped but is %q.", pgnLineLength, got)
+	}
+}
+
+const operaGame = `[Event "Paris"]
+[Site "Paris FRA"]
+[Date "1858.??.??"]
+[Round "?"]
+[White "Paul Morphy"]
+[Black "Duke Karl / Count Isouard"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 d6 3. d4 Bg4 {This is a weak move already.} 4. dxe5 Bxf3 5. Qxf3
+dxe5 6. Bc4 Nf6 7. Qb3 Qe7 8. Nc3 c6 9. Bg5 b5?! (9... Qb4 10. Qxb4 (10. Bxf6 gxf6)
+10... Nxe4) 10. Nxb5! cxb5 11. Bxb5+ Nbd7 12. O-O-O Rd8 13. Rxd7 $1 Rxd7 14. Rd1
+Qe6 ; the queen has to cover d7
+15. Bxd7+ Nxd7 16. Qb8+!! Nxb8 17. Rd8# 1-0
+`
+
+func TestLoadPGN(t *testing.T) {
+	pgn := "% exported by some tool\n" + operaGame + `
+[Event "Endgame"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+
+1.e4 Kd7 2.e5 *
+`
+	positions, err := LoadPGN(strings.NewReader(pgn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 2 {
+		t.Fatalf("PGN should contain 2 games but contains %d.", len(positions))
+	}
+	if opera := positions[0]; opera.status != WhiteWins || opera.reason != Checkmate || len(opera.history) != 33 {
+		t.Errorf("Game 1 should end with %v by %v after 33 plies but is %v by %v after %d plies.", WhiteWins,
+			Checkmate, opera.status, opera.reason, len(opera.history))
+	}
+	want := "8/3k4/8/4P3/8/8/8/4K3 b - - 0 2"
+	if got := positions[1].FEN(); got != want {
+		t.Errorf("Game 2 should end in %q but ends in %q.", want, got)
+	}
+}
+
+func TestLoadPGNRoundTrip(t *testing.T) {
+	positions, err := LoadPGN(strings.NewReader(operaGame))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported := positions[0].PGN(nil)
+	again, err := LoadPGN(strings.NewReader(exported))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := again[0].FEN(), positions[0].FEN(); got != want {
+		t.Errorf("Exported game should replay to %q but replays to %q.", want, got)
+	}
+}
+
+func TestLoadPGNKeepsTagsAndResult(t *testing.T) {
+	positions, err := LoadPGN(strings.NewReader(`[White "Ann"] 1. e4 e5 2. Nf3 1-0`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := positions[0]
+	exported := pos.PGN(nil)
+	for _, want := range []string{"[White \"Ann\"]\n", "[Result \"1-0\"]\n", "\n1. e4 e5 2. Nf3 1-0\n"} {
+		if !strings.Contains(exported, want) {
+			t.Errorf("Exported game should contain %q but is:\n%s", want, exported)
+		}
+	}
+	again, err := LoadPGN(strings.NewReader(exported))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := again[0].PGN(nil); got != exported {
+		t.Errorf("Reloaded game should export as\n%s\nbut exports as\n%s", exported, got)
+	}
+	if err := pos.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if got := pos.PGN(nil); !strings.Contains(got, "[White \"Ann\"]\n") || !strings.HasSuffix(got, "1. e4 e5 *\n") {
+		t.Errorf("Game after undo should keep its tags but not its result, but is:\n%s", got)
+	}
+}
+
+func TestReadPGN(t *testing.T) {
+	games, err := readPGN(strings.NewReader(operaGame))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 1 {
+		t.Fatalf("PGN should contain 1 game but contains %d.", len(games))
+	}
+	game := games[0]
+	if game.tags["Black"] != "Duke Karl / Count Isouard" || game.tags["Date"] != "1858.??.??" {
+		t.Errorf("Tags are parsed incorrectly: %v", game.tags)
+	}
+	if game.result != "1-0" || len(game.moves) != 33 || game.moves[17] != "b5" || game.moves[32] != "Rd8#" {
+		t.Errorf("Movetext is parsed incorrectly: %v %v", game.moves, game.result)
+	}
+}
+
+func TestReadPGNEscapedTag(t *testing.T) {
+	games, err := readPGN(strings.NewReader(`[White "Anna \"The Quick\" \\ Smith"]` + "\n\n1. e4 *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `Anna "The Quick" \ Smith`; games[0].tags["White"] != want {
+		t.Errorf("Tag should be %q but is %q.", want, games[0].tags["White"])
+	}
+}
+
+func TestLoadPGNError(t *testing.T) {
+	tests := []struct {
+		pgn  string
+		want string
+	}{
+		{"1. e4 e5 *\n\n1. e4 e5 2. Ke3 *", "Game 2, ply 3:"},
+		{"1. e4 {unclosed", "Game 1, line 1:"},
+		{"1. e4 e5 *\n[White \"x\"\n1. d4 *", "Game 2, line 2:"},
+		{"1. e4 (1. d4 *", "Game 1, line 1:"},
+		{"1. e4 ) *", "Game 1, line 1:"},
+		{"[FEN \"8/8/8/8/8/8/8/8 w - - 0 1\"]\n1. e4 *", "Game 1:"},
+	}
+	for _, test := range tests {
+		_, err := LoadPGN(strings.NewReader(test.pgn))
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("Loading PGN %q should error with %q but errors with %v.", test.pgn, test.want, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package chess
+
+type Piece uint8
+type PieceKind uint8
+
+const (
+	NoKind PieceKind = iota
+	King
+	Queen
+	Rook
+	Bishop
+	Knight
+	Pawn
+)
+
+const blackPiece Piece = 8
+
+const empty Piece = 0
+const (
+	wking Piece = Piece(King) + iota
+	wqueen
+	wrook
+	wbishop
+	wknight
+	wpawn
+)
+const (
+	bking Piece = blackPiece + Piece(King) + iota
+	bqueen
+	brook
+	bbishop
+	bknight
+	bpawn
+)
+
+func NewPiece(kind PieceKind, player Color) Piece {
+	if kind == NoKind || kind > Pawn {
+		return empty
+	}
+	if player == Black {
+		return blackPiece | Piece(kind)
+	}
+	return Piece(kind)
+}
+
+func (p Piece) Kind() PieceKind {
+	return PieceKind(p &^ blackPiece)
+}
+
+func (p Piece) Color() Color {
+	return p&blackPiece == 0
+}
+
+func (p Piece) index() int {
+	return int(p.Kind()-King) + 6*p.Color().index()
+}
+
+func colored(piece Piece, player Color) Piece {
+	return NewPiece(piece.Kind(), player)
+}
+
+func (p Piece) String() string {
+	return string(UnicodePieces.glyph(p))
+}
+
+func (kind PieceKind) String() string {
+	switch kind {
+	case King:
+		return "king"
+	case Queen:
+		return "queen"
+	case Rook:
+		return "rook"
+	case Bishop:
+		return "bishop"
+	case Knight:
+		return "knight"
+	case Pawn:
+		return "pawn"
+	}
+	return "none"
+}
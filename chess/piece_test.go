@@ -0,0 +1,56 @@
+package chess
+
+import (
+	"testing"
+)
+
+func TestPieceAccessors(t *testing.T) {
+	tests := []struct {
+		piece Piece
+		kind  PieceKind
+		color Color
+	}{
+		{wking, King, White},
+		{wpawn, Pawn, White},
+		{bqueen, Queen, Black},
+		{bknight, Knight, Black},
+	}
+	for _, test := range tests {
+		if test.piece.Kind() != test.kind || test.piece.Color() != test.color {
+			t.Errorf("Piece %v should be a %v %d but is a %v %d.", test.piece, test.color, test.kind,
+				test.piece.Color(), test.piece.Kind())
+		}
+		if got := NewPiece(test.kind, test.color); got != test.piece {
+			t.Errorf("New %v piece of kind %d should be %v but is %v.", test.color, test.kind, test.piece, got)
+		}
+		if got := colored(test.piece, !test.color); got.Kind() != test.kind || got.Color() == test.color {
+			t.Errorf("Piece %v colored for %v should keep its kind but is %v.", test.piece, !test.color, got)
+		}
+	}
+	if empty.Kind() != NoKind || NewPiece(NoKind, Black) != empty || colored(empty, Black) != empty {
+		t.Error("Empty square should have no kind and stay empty when colored.")
+	}
+}
+
+func TestPieceIndex(t *testing.T) {
+	seen := make(map[int]Piece)
+	for _, p := range []Piece{wking, wqueen, wrook, wbishop, wknight, wpawn, bking, bqueen, brook, bbishop, bknight,
+		bpawn} {
+		i := p.index()
+		if i < 0 || i >= 12 {
+			t.Errorf("Index of %v should be between 0 and 11 but is %d.", p, i)
+		}
+		if other, ok := seen[i]; ok {
+			t.Errorf("Pieces %v and %v share index %d.", p, other, i)
+		}
+		seen[i] = p
+	}
+}
+
+func TestNewPieceInvalidKind(t *testing.T) {
+	for _, kind := range []PieceKind{NoKind, Pawn + 1, PieceKind(Rook | 8), 255} {
+		if p := NewPiece(kind, White); p != empty {
+			t.Errorf("NewPiece(%d) should be empty but is %v", kind, p)
+		}
+	}
+}
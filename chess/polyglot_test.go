@@ -0,0 +1,118 @@
+package chess
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func bookData(entries ...bookEntry) []byte {
+	var data []byte
+	for _, entry := range entries {
+		data = binary.BigEndian.AppendUint64(data, entry.key)
+		data = binary.BigEndian.AppendUint16(data, entry.move)
+		data = binary.BigEndian.AppendUint16(data, entry.weight)
+		data = binary.BigEndian.AppendUint32(data, 0)
+	}
+	return data
+}
+
+func bookMove(from, to Square, promotion int) uint16 {
+	return uint16(promotion<<12 | from.index()<<6 | to.index())
+}
+
+func TestPolyglotKey(t *testing.T) {
+	tests := []struct {
+		moves []string
+		key   uint64
+	}{
+		{nil, 0x463b96181691fc9c},
+		{[]string{"e2e4"}, 0x823c9b50fd114196},
+		{[]string{"e2e4", "d7d5"}, 0x0756b94461c50fb0},
+		{[]string{"e2e4", "d7d5", "e4e5"}, 0x662fafb965db29d4},
+		{[]string{"e2e4", "d7d5", "e4e5", "f7f5"}, 0x22a48b5a8e47ff78},
+		{[]string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2"}, 0x652a607ca3f242c1},
+		{[]string{"e2e4", "d7d5", "e4e5", "f7f5", "e1e2", "e8f7"}, 0x00fdd303c946bdd9},
+		{[]string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4"}, 0x3c8123ea7b067637},
+		{[]string{"a2a4", "b7b5", "h2h4", "b5b4", "c2c4", "b4c3", "a1a3"}, 0x5c3f9b829b279560},
+	}
+	for _, test := range tests {
+		pos := NewPosition()
+		for _, input := range test.moves {
+			m, err := pos.ParseMove(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := pos.Apply(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if key := pos.PolyglotKey(); key != test.key {
+			t.Errorf("Polyglot key after %v should be %016x but is %016x", test.moves, test.key, key)
+		}
+	}
+}
+
+func TestBookMoves(t *testing.T) {
+	start := NewPosition()
+	castling, err := ParseFEN("r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	promotion, err := ParseFEN("8/P6k/8/8/8/8/8/K7 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	book, err := ParseBook(bookData(
+		bookEntry{castling.PolyglotKey(), bookMove(Square{_e, _1}, Square{_a, _1}, 0), 3},
+		bookEntry{start.PolyglotKey(), bookMove(Square{_d, _2}, Square{_d, _4}, 0), 5},
+		bookEntry{start.PolyglotKey(), bookMove(Square{_e, _2}, Square{_e, _5}, 0), 20},
+		bookEntry{start.PolyglotKey(), bookMove(Square{_e, _2}, Square{_e, _4}, 0), 10},
+		bookEntry{castling.PolyglotKey(), bookMove(Square{_e, _1}, Square{_h, _1}, 0), 7},
+		bookEntry{promotion.PolyglotKey(), bookMove(Square{_a, _7}, Square{_a, _8}, 2), 1},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		position *Position
+		want     []string
+	}{
+		{start, []string{"e2e4", "d2d4"}},
+		{castling, []string{"e1g1", "e1c1"}},
+		{promotion, []string{"a7a8b"}},
+	}
+	for _, test := range tests {
+		var moves []string
+		for _, m := range book.Moves(test.position) {
+			moves = append(moves, m.Move.UCI())
+		}
+		if len(moves) != len(test.want) {
+			t.Errorf("Book moves should be %v but are %v", test.want, moves)
+			continue
+		}
+		for i := range moves {
+			if moves[i] != test.want[i] {
+				t.Errorf("Book moves should be %v but are %v", test.want, moves)
+				break
+			}
+		}
+	}
+	if m, ok := book.Pick(start, HighestWeight); !ok || m.UCI() != "e2e4" {
+		t.Errorf("Best book move should be e2e4 but is %v", m)
+	}
+	for range 20 {
+		if m, ok := book.Pick(start, WeightedRandom); !ok || m.UCI() != "e2e4" && m.UCI() != "d2d4" {
+			t.Errorf("Random book move should be e2e4 or d2d4 but is %v", m)
+		}
+	}
+	empty, _ := ParseFEN("8/8/8/4k3/8/8/4P3/4K3 w - - 0 1")
+	if m, ok := book.Pick(empty, HighestWeight); ok {
+		t.Errorf("Position outside the book should have no book move but has %v", m)
+	}
+}
+
+func TestParseBookInvalid(t *testing.T) {
+	if _, err := ParseBook(make([]byte, 17)); err == nil {
+		t.Error("Book with a partial entry should be rejected but is not")
+	}
+}
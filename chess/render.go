@@ -0,0 +1,98 @@
+package chess
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type PieceSet map[Piece]rune
+
+var UnicodePieces = PieceSet{
+	empty:   ' ',
+	wking:   '♔',
+	wqueen:  '♕',
+	wrook:   '♖',
+	wbishop: '♗',
+	wknight: '♘',
+	wpawn:   '♙',
+	bking:   '♚',
+	bqueen:  '♛',
+	brook:   '♜',
+	bbishop: '♝',
+	bknight: '♞',
+	bpawn:   '♟',
+}
+
+var ASCIIPieces = letterPieces("KQRBNP")
+
+var pieceSets = map[string]PieceSet{
+	"unicode": UnicodePieces,
+	"ascii":   ASCIIPieces,
+	"de":      letterPieces("KDTLSB"),
+	"es":      letterPieces("RDTACP"),
+	"fr":      letterPieces("RDTFCP"),
+	"it":      letterPieces("RDTACP"),
+}
+
+func letterPieces(letters string) PieceSet {
+	set := PieceSet{empty: ' '}
+	for i, letter := range []rune(letters) {
+		kind := King + PieceKind(i)
+		set[NewPiece(kind, White)] = letter
+		set[NewPiece(kind, Black)] = []rune(strings.ToLower(string(letter)))[0]
+	}
+	return set
+}
+
+func (set PieceSet) glyph(p Piece) rune {
+	if r, ok := set[p]; ok {
+		return r
+	}
+	return '?'
+}
+
+func (set PieceSet) Format(pieces []Piece) string {
+	var b strings.Builder
+	for _, p := range pieces {
+		b.WriteRune(set.glyph(p))
+	}
+	return b.String()
+}
+
+func ParsePieceSet(name string) (PieceSet, error) {
+	set, ok := pieceSets[name]
+	if !ok {
+		names := make([]string, 0, len(pieceSets))
+		for name := range pieceSets {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return nil, fmt.Errorf("Piece set %q is unknown, want one of %s.", name, strings.Join(names, ", "))
+	}
+	return set, nil
+}
+
+func (board *board) formatb() string {
+	return board.format(UnicodePieces)
+}
+
+func (board *board) format(set PieceSet) (s string) {
+	for row := _8; row >= _1; row-- {
+		if row < _8 {
+			s += "\n"
+		}
+		s += fmt.Sprintf("%d ", row+1)
+		s += "\033[38;5;0m"
+		for file := _a; file <= _h; file++ {
+			if (row+file)%2 == 0 {
+				s += fmt.Sprintf("\033[48;5;250m%c ", set.glyph(board.at(Square{file, row})))
+			} else {
+				s += fmt.Sprintf("\033[48;5;15m%c ", set.glyph(board.at(Square{file, row})))
+			}
+		}
+		s += "\033[0m"
+	}
+	s += "\n  a b c d e f g h"
+	return
+}
@@ -0,0 +1,48 @@
+package chess
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPieceSets(t *testing.T) {
+	tests := []struct {
+		set    string
+		pieces []Piece
+		want   string
+	}{
+		{"unicode", []Piece{wking, bqueen, wpawn, bknight}, "♔♛♙♞"},
+		{"ascii", []Piece{wking, bqueen, wpawn, bknight}, "KqPn"},
+		{"de", []Piece{wking, bqueen, wrook, bbishop, wknight, bpawn}, "KdTlSb"},
+		{"fr", []Piece{wking, bqueen, wrook, bbishop, wknight, bpawn}, "RdTfCp"},
+	}
+	for _, test := range tests {
+		set, err := ParsePieceSet(test.set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := set.Format(test.pieces); got != test.want {
+			t.Errorf("Pieces in set %q should be %q but are %q.", test.set, test.want, got)
+		}
+	}
+}
+
+func TestParsePieceSetError(t *testing.T) {
+	if _, err := ParsePieceSet("klingon"); err == nil || !strings.Contains(err.Error(), "ascii") {
+		t.Errorf("Unknown piece set should error and list the known ones but gives %v.", err)
+	}
+}
+
+func TestBoardFormat(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	got := pos.board.format(ASCIIPieces)
+	for _, want := range []string{"R \033[48;5;15mN ", "p \033[48;5;250mp "} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ASCII board should contain %q but is:\n%s", want, got)
+		}
+	}
+	if strings.ContainsAny(got, "♔♚") {
+		t.Errorf("ASCII board should not contain figurines but is:\n%s", got)
+	}
+}
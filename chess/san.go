@@ -0,0 +1,211 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+func (position *Position) sanWithoutSuffix(m move) string {
+	if m.piece.Kind() == King && abs(m.to.file-m.from.file) == 2 {
+		if m.to.file == _g {
+			return "O-O"
+		}
+		return "O-O-O"
+	}
+	var b strings.Builder
+	isCapture := m.captured != empty
+	if m.piece.Kind() == Pawn {
+		if isCapture {
+			b.WriteRune(rune(m.from.file + fileUnicodeOffset))
+		}
+	} else {
+		b.WriteRune(sanLetter(m.piece))
+		b.WriteString(position.disambiguation(m))
+	}
+	if isCapture {
+		b.WriteRune('x')
+	}
+	b.WriteString(m.to.String())
+	if m.promotion != empty {
+		b.WriteRune('=')
+		b.WriteRune(sanLetter(m.promotion))
+	}
+	return b.String()
+}
+
+func (position *Position) disambiguation(m move) string {
+	var isAmbiguous, sameFile, sameRow bool
+	var list moveList
+	position.generateMoves(&list)
+	for _, other := range list.slice() {
+		from := other.From()
+		if from == m.from || other.To() != m.to || position.board.at(from) != m.piece {
+			continue
+		}
+		isAmbiguous = true
+		sameFile = sameFile || from.file == m.from.file
+		sameRow = sameRow || from.row == m.from.row
+	}
+	if !isAmbiguous {
+		return ""
+	}
+	if !sameFile {
+		return string(rune(m.from.file + fileUnicodeOffset))
+	}
+	if !sameRow {
+		return string(rune(m.from.row + rowUnicodeOffset))
+	}
+	return m.from.String()
+}
+
+func (position *Position) sanSuffix() string {
+	if position.reason == Checkmate {
+		return "#"
+	}
+	if position.InCheck() {
+		return "+"
+	}
+	return ""
+}
+
+func (position *Position) SAN(m Move) (string, error) {
+	clone := position.Clone()
+	if err := clone.Apply(m); err != nil {
+		return "", err
+	}
+	return clone.history[len(clone.history)-1].san, nil
+}
+
+func (position *Position) FormatMoveList() string {
+	return strings.Join(position.movetext(), " ")
+}
+
+func sanLetter(piece Piece) rune {
+	return unicode.ToUpper(fenLetter(piece))
+}
+
+var sanPattern = regexp.MustCompile(`^(?:(O-O-O|O-O)|([KQRBN])?([a-h])?([1-8])?(x)?([a-h][1-8])(?:=?([QRBN]))?)[+#]?$`)
+
+var pieceNames = map[rune]string{
+	'K': "king",
+	'Q': "queen",
+	'R': "rook",
+	'B': "bishop",
+	'N': "knight",
+	'P': "pawn",
+}
+
+func (position *Position) parseSAN(s string) (move, error) {
+	if position.status != Ongoing {
+		return move{}, gameOverError(position.status, position.reason)
+	}
+	match := sanPattern.FindStringSubmatch(strings.ReplaceAll(s, "0", "O"))
+	if match == nil {
+		return move{}, fmt.Errorf("Move %q does not match format", s)
+	}
+	castling, letter, fromFile, fromRow, capture, to, promotion := match[1], match[2], match[3], match[4],
+		match[5], match[6], match[7]
+	if letter == "" {
+		letter = "P"
+	}
+	var candidates []move
+	needsPromotion := false
+	for _, m := range position.validMoves() {
+		isCastling := m.piece.Kind() == King && abs(m.to.file-m.from.file) == 2
+		if castling != "" {
+			if isCastling && (castling == "O-O") == (m.to.file == _g) {
+				candidates = append(candidates, m)
+			}
+			continue
+		}
+		isPawn := m.piece.Kind() == Pawn
+		if string(sanLetter(m.piece)) != letter || m.to.String() != to ||
+			(fromFile != "" && m.from.String()[0] != fromFile[0]) ||
+			(fromRow != "" && m.from.String()[1] != fromRow[0]) ||
+			(isPawn && fromFile == "" && m.from.file != m.to.file) {
+			continue
+		}
+		if promotion == "" && m.promotion != empty {
+			needsPromotion = true
+			continue
+		}
+		if promotion != "" && (m.promotion == empty || string(sanLetter(m.promotion)) != promotion) {
+			continue
+		}
+		candidates = append(candidates, m)
+	}
+	switch {
+	case len(candidates) == 1 && capture != "" && candidates[0].captured == empty:
+		return move{}, fmt.Errorf("Move %q is not a capture.", s)
+	case len(candidates) == 1:
+		return candidates[0], nil
+	case len(candidates) > 1:
+		var sans []string
+		for _, m := range candidates {
+			sans = append(sans, position.sanWithoutSuffix(m))
+		}
+		slices.Sort(sans)
+		return move{}, fmt.Errorf("Move %q is ambiguous, it could be %s.", s, strings.Join(sans, " or "))
+	case castling != "":
+		if err := position.explainCastling(castling); err != nil {
+			return move{}, err
+		}
+		return move{}, fmt.Errorf("Castling %q is not allowed.", s)
+	case needsPromotion:
+		return move{}, fmt.Errorf("Move %q needs a promotion piece, e.g. %s=Q.", s, strings.TrimRight(s, "+#"))
+	}
+	if err := position.explainSAN(letter, fromFile, fromRow, to, promotion); err != nil {
+		return move{}, err
+	}
+	return move{}, fmt.Errorf("No %s can move to %s.", pieceNames[rune(letter[0])], to)
+}
+
+func (position *Position) explainCastling(castling string) error {
+	king := Square{_e, _1}
+	if position.turn == Black {
+		king = Square{_e, _8}
+	}
+	if position.board.at(king) != colored(wking, position.turn) {
+		return nil
+	}
+	to := Square{_g, king.row}
+	if castling == "O-O-O" {
+		to = Square{_c, king.row}
+	}
+	_, err := position.check(king, to, empty)
+	return err
+}
+
+func (position *Position) explainSAN(letter, fromFile, fromRow, to, promotion string) error {
+	target, err := ParseSquare(to)
+	if err != nil {
+		return nil
+	}
+	var promotionPiece Piece
+	if promotion != "" {
+		promotionPiece = fenPieces[rune(promotion[0])]
+	}
+	var explanation error
+	pieces := position.board.bitboardOf(colored(fenPieces[rune(letter[0])], position.turn))
+	for pieces != 0 {
+		from := squareAt(pieces.pop())
+		if (fromFile != "" && from.String()[0] != fromFile[0]) ||
+			(fromRow != "" && from.String()[1] != fromRow[0]) ||
+			(letter == "P" && fromFile == "" && from.file != target.file) {
+			continue
+		}
+		_, err := position.check(from, target, promotionPiece)
+		if err == nil || errors.Is(err, ErrIllegalGeometry) {
+			continue
+		}
+		if explanation != nil {
+			return nil
+		}
+		explanation = err
+	}
+	return explanation
+}
@@ -0,0 +1,182 @@
+package chess
+
+import (
+	"testing"
+)
+
+func TestMoveSAN(t *testing.T) {
+	tests := []struct {
+		fen       string
+		from, to  Square
+		promotion Piece
+		want      string
+	}{
+		{StartingFEN, Square{_e, _2}, Square{_e, _4}, empty, "e4"},
+		{StartingFEN, Square{_g, _1}, Square{_f, _3}, empty, "Nf3"},
+		{"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", Square{_e, _4}, Square{_d, _5}, empty, "exd5"},
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", Square{_e, _5}, Square{_d, _6}, empty, "exd6"},
+		{"r3k3/8/8/8/8/8/8/4K2R w K - 0 1", Square{_e, _1}, Square{_g, _1}, empty, "O-O"},
+		{"r3k3/8/8/8/8/8/8/3K3R b q - 0 1", Square{_e, _8}, Square{_c, _8}, empty, "O-O-O+"},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", Square{_b, _7}, Square{_b, _8}, wqueen, "b8=Q+"},
+		{"n3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", Square{_b, _7}, Square{_a, _8}, wknight, "bxa8=N"},
+		{"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", Square{_a, _1}, Square{_d, _1}, empty, "Rad1"},
+		{"4k3/8/8/8/R7/8/8/R3K3 w - - 0 1", Square{_a, _1}, Square{_a, _2}, empty, "R1a2"},
+		{"4k3/8/8/8/8/8/8/R1R1K3 w - - 0 1", Square{_c, _1}, Square{_b, _1}, empty, "Rcb1"},
+		{"8/8/7k/8/Q2Q4/8/8/Q3K3 w - - 0 1", Square{_a, _4}, Square{_d, _1}, empty, "Qa4d1"},
+		{"4k3/8/8/8/8/8/8/R3KN1R w - - 0 1", Square{_h, _1}, Square{_g, _1}, empty, "Rg1"},
+		{"4k3/8/8/8/7b/6N1/8/2N1K3 w - - 0 1", Square{_c, _1}, Square{_e, _2}, empty, "Ne2"},
+		{"6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1", Square{_a, _1}, Square{_a, _8}, empty, "Ra8#"},
+	}
+	for _, test := range tests {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pos.move(test.from, test.to, test.promotion); err != nil {
+			t.Errorf("Move from %v to %v in %q should be legal but errors: %v", test.from, test.to, test.fen, err)
+			continue
+		}
+		if got := pos.history[0].san; got != test.want {
+			t.Errorf("SAN of move from %v to %v in %q is wrong. Want %q but got %q.", test.from, test.to, test.fen,
+				test.want, got)
+		}
+	}
+}
+
+func TestParseSAN(t *testing.T) {
+	tests := []struct {
+		fen       string
+		san       string
+		from, to  Square
+		promotion Piece
+	}{
+		{StartingFEN, "e4", Square{_e, _2}, Square{_e, _4}, empty},
+		{StartingFEN, "Nf3", Square{_g, _1}, Square{_f, _3}, empty},
+		{StartingFEN, "Ngf3", Square{_g, _1}, Square{_f, _3}, empty},
+		{StartingFEN, "Ng1f3", Square{_g, _1}, Square{_f, _3}, empty},
+		{"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "exd5", Square{_e, _4}, Square{_d, _5}, empty},
+		{"4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1", "e5", Square{_e, _4}, Square{_e, _5}, empty},
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "exd6", Square{_e, _5}, Square{_d, _6}, empty},
+		{"r3k3/8/8/8/8/8/8/4K2R w K - 0 1", "O-O", Square{_e, _1}, Square{_g, _1}, empty},
+		{"r3k3/8/8/8/8/8/8/4K2R w K - 0 1", "0-0+", Square{_e, _1}, Square{_g, _1}, empty},
+		{"r3k3/8/8/8/8/8/8/3K3R b q - 0 1", "O-O-O+", Square{_e, _8}, Square{_c, _8}, empty},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8=Q+", Square{_b, _7}, Square{_b, _8}, wqueen},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8N", Square{_b, _7}, Square{_b, _8}, wknight},
+		{"n3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "bxa8=R", Square{_b, _7}, Square{_a, _8}, wrook},
+		{"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "Rad1", Square{_a, _1}, Square{_d, _1}, empty},
+		{"4k3/8/8/8/R7/8/8/R3K3 w - - 0 1", "R1a2", Square{_a, _1}, Square{_a, _2}, empty},
+		{"8/8/7k/8/Q2Q4/8/8/Q3K3 w - - 0 1", "Qa4d1", Square{_a, _4}, Square{_d, _1}, empty},
+		{"4k3/8/8/8/7b/6N1/8/2N1K3 w - - 0 1", "Ne2", Square{_c, _1}, Square{_e, _2}, empty},
+		{"6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1", "Ra8#", Square{_a, _1}, Square{_a, _8}, empty},
+	}
+	for _, test := range tests {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := pos.parseSAN(test.san)
+		if err != nil || m.from != test.from || m.to != test.to || m.promotion != test.promotion {
+			t.Errorf("SAN %q in %q should be %v-%v=%v but is %v-%v=%v: %v", test.san, test.fen, test.from, test.to,
+				test.promotion, m.from, m.to, m.promotion, err)
+		}
+	}
+}
+
+func TestParseSANError(t *testing.T) {
+	tests := []struct {
+		fen  string
+		san  string
+		want string
+	}{
+		{StartingFEN, "e5", `No pawn can move to e5.`},
+		{StartingFEN, "Nd4", `No knight can move to d4.`},
+		{StartingFEN, "Nd2", `Knight on b1 cannot capture own pawn on d2.`},
+		{StartingFEN, "Pe4", `Move "Pe4" does not match format`},
+		{StartingFEN, "xyz", `Move "xyz" does not match format`},
+		{StartingFEN, "O-O", `Castling from e1 to g1 is blocked by bishop on f1.`},
+		{"4k3/7p/8/8/8/8/8/3K4 w - - 0 1", "O-O", `Castling "O-O" is not allowed.`},
+		{"4k3/8/8/q7/8/8/3B4/4K3 w - - 0 1", "Be3", `Bishop on d2 is pinned against king on e1 by queen on a5.`},
+		{StartingFEN, "Bg5", `Bishop on c1 cannot move to g5, it is blocked by pawn on d2.`},
+		{StartingFEN, "Nxf3", `Move "Nxf3" is not a capture.`},
+		{"4k3/8/8/8/8/8/4K3/R6R w - - 0 1", "Rd1", `Move "Rd1" is ambiguous, it could be Rad1 or Rhd1.`},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8+", `Move "b8+" needs a promotion piece, e.g. b8=Q.`},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b8=K", `Move "b8=K" does not match format`},
+		{"4k3/8/8/8/8/8/8/3qK3 w - - 0 1", "Kxd1", ``},
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", "Kh7", `Game is over: draw by stalemate.`},
+	}
+	for _, test := range tests {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = pos.parseSAN(test.san)
+		if test.want == "" {
+			if err != nil {
+				t.Errorf("SAN %q in %q should not error but errors: %v", test.san, test.fen, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != test.want {
+			t.Errorf("SAN %q in %q should error with %q but errors with %v.", test.san, test.fen, test.want, err)
+		}
+	}
+}
+
+func TestFormatMoveList(t *testing.T) {
+	var pos Position
+	pos.startingPos()
+	for _, san := range []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "a6", "Bxc6", "dxc6", "O-O"} {
+		m, err := pos.parseSAN(san)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pos.move(m.from, m.to, m.promotion); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Bxc6 dxc6 5. O-O"
+	if got := pos.FormatMoveList(); got != want {
+		t.Errorf("Move list is wrong. Want %q but got %q.", want, got)
+	}
+}
+
+func TestFormatMoveListBlackToMove(t *testing.T) {
+	pos, err := ParseFEN("4k3/8/8/8/8/8/8/R3K3 b - - 0 12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moves := [][2]Square{
+		{{_e, _8}, {_d, _7}},
+		{{_a, _1}, {_a, _7}},
+	}
+	for _, m := range moves {
+		if err := pos.move(m[0], m[1], empty); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "12... Kd7 13. Ra7+"
+	if got := pos.FormatMoveList(); got != want {
+		t.Errorf("Move list is wrong. Want %q but got %q.", want, got)
+	}
+}
+
+func TestPositionSAN(t *testing.T) {
+	pos, err := ParseFEN("6k1/5ppp/8/8/8/8/8/R3K3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := pos.FEN()
+	m, err := pos.ParseMove("a1a8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := pos.SAN(m); err != nil || got != "Ra8#" {
+		t.Errorf("SAN of a1a8 should be Ra8# but is %q (error %v)", got, err)
+	}
+	if got := pos.FEN(); got != before || len(pos.history) != 0 {
+		t.Errorf("SAN should leave the position at %q but changes it to %q", before, got)
+	}
+	if _, err := pos.SAN(newMove(Square{_a, _1}, Square{_b, _2}, empty, 0)); err == nil {
+		t.Error("SAN of an illegal move should fail but does not")
+	}
+}
@@ -0,0 +1,298 @@
+package chess
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	maxPly     = 64
+	infinity   = 32000
+	mateScore  = 31000
+	checkNodes = 2048
+)
+
+type Limits struct {
+	Depth int
+	Nodes int
+	Time  time.Duration
+	Table *TranspositionTable
+}
+
+type SearchInfo struct {
+	Depth    int
+	Score    int
+	Mate     int
+	Nodes    int
+	Time     time.Duration
+	PV       []Move
+	HashFull int
+}
+
+type searcher struct {
+	position Position
+	table    *TranspositionTable
+	ctx      context.Context
+	deadline time.Time
+	nodes    int
+	maxNodes int
+	stopped  bool
+	pv       [maxPly + 1][maxPly + 1]Move
+	pvLength [maxPly + 1]int
+	previous []Move
+}
+
+func (position *Position) Search(ctx context.Context, limits Limits, report func(SearchInfo)) (SearchInfo, error) {
+	if position.status != Ongoing {
+		return SearchInfo{}, gameOverError(position.status, position.reason)
+	}
+	var list moveList
+	position.generateMoves(&list)
+	if list.size == 0 {
+		return SearchInfo{}, fmt.Errorf("No legal moves to search.")
+	}
+	start := time.Now()
+	s := searcher{position: *position.Clone(), table: limits.Table, ctx: ctx, maxNodes: limits.Nodes}
+	if s.table == nil {
+		s.table = NewTranspositionTable(1)
+	}
+	s.table.newSearch()
+	if limits.Time > 0 {
+		s.deadline = start.Add(limits.Time)
+	}
+	maxDepth := limits.Depth
+	if maxDepth <= 0 || maxDepth > maxPly {
+		maxDepth = maxPly
+	}
+	best := SearchInfo{PV: []Move{list.moves[0]}}
+	for depth := 1; depth <= maxDepth; depth++ {
+		score := s.negamax(depth, 0, -infinity, infinity)
+		if s.stopped {
+			break
+		}
+		s.previous = s.extendPV(slices.Clone(s.pv[0][:s.pvLength[0]]), depth)
+		best = SearchInfo{Depth: depth, Score: score, Mate: mateIn(score), Nodes: s.nodes, Time: time.Since(start),
+			PV: s.previous, HashFull: s.table.HashFull()}
+		if report != nil {
+			report(best)
+		}
+		if best.Mate != 0 && abs(best.Mate)*2 <= depth {
+			break
+		}
+		if limits.Time > 0 && time.Since(start) > limits.Time/2 {
+			break
+		}
+	}
+	best.Nodes, best.Time, best.HashFull = s.nodes, time.Since(start), s.table.HashFull()
+	return best, nil
+}
+
+func (s *searcher) extendPV(pv []Move, depth int) []Move {
+	position := &s.position
+	for _, m := range pv {
+		position.makeMove(m)
+	}
+	for len(pv) < depth {
+		entry, found := s.table.probe(position.Hash())
+		if !found || entry.move == 0 || position.halfmoveClock >= 100 || position.repeated() {
+			break
+		}
+		var list moveList
+		position.generateMoves(&list)
+		if !slices.Contains(list.slice(), entry.move) {
+			break
+		}
+		position.makeMove(entry.move)
+		pv = append(pv, entry.move)
+	}
+	for range pv {
+		position.unmakeMove()
+	}
+	return pv
+}
+
+func mateIn(score int) int {
+	switch {
+	case score > mateScore-maxPly:
+		return (mateScore - score + 1) / 2
+	case score < -mateScore+maxPly:
+		return -(mateScore + score) / 2
+	}
+	return 0
+}
+
+func (s *searcher) checkStop() {
+	if s.maxNodes > 0 && s.nodes >= s.maxNodes {
+		s.stopped = true
+		return
+	}
+	if s.nodes%checkNodes != 0 {
+		return
+	}
+	if !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopped = true
+	}
+	select {
+	case <-s.ctx.Done():
+		s.stopped = true
+	default:
+	}
+}
+
+func (s *searcher) negamax(depth, ply, alpha, beta int) int {
+	position := &s.position
+	s.pvLength[ply] = ply
+	s.nodes++
+	s.checkStop()
+	if s.stopped {
+		return 0
+	}
+	if ply > 0 && (position.halfmoveClock >= 100 || position.repeated() || position.board.insufficientMaterial()) {
+		return 0
+	}
+	if ply >= maxPly {
+		return position.evaluate()
+	}
+	inCheck := position.InCheck()
+	if inCheck {
+		depth++
+	}
+	if depth <= 0 {
+		return s.quiesce(ply, alpha, beta)
+	}
+	key := position.Hash()
+	entry, found := s.table.probe(key)
+	var hashMove Move
+	if found {
+		hashMove = entry.move
+	}
+	if found && ply > 0 && int(entry.depth) >= depth {
+		score := scoreFromTable(int(entry.score), ply)
+		if entry.bound == exactBound || entry.bound == lowerBound && score >= beta ||
+			entry.bound == upperBound && score <= alpha {
+			return score
+		}
+	}
+	var list moveList
+	position.generateMoves(&list)
+	if list.size == 0 {
+		if inCheck {
+			return -mateScore + ply
+		}
+		return 0
+	}
+	s.order(list.slice(), ply, hashMove)
+	nodeBound, bestMove := upperBound, Move(0)
+	for _, m := range list.slice() {
+		position.makeMove(m)
+		score := -s.negamax(depth-1, ply+1, -beta, -alpha)
+		position.unmakeMove()
+		if s.stopped {
+			return 0
+		}
+		if score > alpha {
+			alpha, nodeBound, bestMove = score, exactBound, m
+			s.pv[ply][ply] = m
+			copy(s.pv[ply][ply+1:], s.pv[ply+1][ply+1:s.pvLength[ply+1]])
+			s.pvLength[ply] = s.pvLength[ply+1]
+			if score >= beta {
+				s.table.store(key, depth, lowerBound, scoreToTable(beta, ply), m)
+				return beta
+			}
+		}
+	}
+	s.table.store(key, depth, nodeBound, scoreToTable(alpha, ply), bestMove)
+	return alpha
+}
+
+func (s *searcher) quiesce(ply, alpha, beta int) int {
+	position := &s.position
+	s.pvLength[ply] = ply
+	s.nodes++
+	s.checkStop()
+	if s.stopped {
+		return 0
+	}
+	standPat := position.evaluate()
+	if standPat >= beta || ply >= maxPly {
+		return standPat
+	}
+	alpha = max(alpha, standPat)
+	var list moveList
+	position.generateMoves(&list)
+	moves := list.slice()
+	moves = slices.DeleteFunc(moves, func(m Move) bool {
+		return !m.is(captureFlag) && m.Promotion() == NoKind
+	})
+	s.order(moves, maxPly, 0)
+	for _, m := range moves {
+		position.makeMove(m)
+		score := -s.quiesce(ply+1, -beta, -alpha)
+		position.unmakeMove()
+		if s.stopped {
+			return 0
+		}
+		if score > alpha {
+			alpha = score
+			if score >= beta {
+				return beta
+			}
+		}
+	}
+	return alpha
+}
+
+func (s *searcher) order(moves []Move, ply int, hashMove Move) {
+	var pvMove Move
+	if ply < len(s.previous) && s.followsPV(ply) {
+		pvMove = s.previous[ply]
+	}
+	var board *board = &(s.position.board)
+	slices.SortStableFunc(moves, func(a, b Move) int {
+		return s.moveOrder(board, b, pvMove, hashMove) - s.moveOrder(board, a, pvMove, hashMove)
+	})
+}
+
+func (s *searcher) followsPV(ply int) bool {
+	undos := s.position.undos[len(s.position.undos)-ply:]
+	for i, u := range undos {
+		if u.move != s.previous[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *searcher) moveOrder(board *board, m Move, pvMove, hashMove Move) int {
+	switch m {
+	case pvMove:
+		return 1 << 21
+	case hashMove:
+		return 1 << 20
+	}
+	score := pieceValues[m.Promotion()]
+	if m.is(captureFlag) {
+		victim := board.at(m.To()).Kind()
+		if m.is(enPassantFlag) {
+			victim = Pawn
+		}
+		score += 10*pieceValues[victim] - pieceValues[board.at(m.From()).Kind()]/10 + 1000
+	}
+	return score
+}
+
+func (position *Position) repeated() bool {
+	n := len(position.repetitions)
+	if n == 0 {
+		return false
+	}
+	key := position.repetitions[n-1]
+	for i := n - 3; i >= 0 && i >= n-1-position.halfmoveClock; i -= 2 {
+		if position.repetitions[i] == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package chess
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSearchFindsMate(t *testing.T) {
+	tests := []struct {
+		fen  string
+		mate int
+		best string
+	}{
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 1, "a1a8"},
+		{"r1bqkb1r/pppp1ppp/2n2n2/4p2Q/2B1P3/8/PPPP1PPP/RNB1K1NR w KQkq - 4 4", 1, "h5f7"},
+		{"kbK5/pp6/1P6/8/8/8/8/R7 w - - 0 1", 2, "a1a6"},
+		{"k7/8/1K6/8/8/8/8/7Q b - - 0 1", -1, "a8b8"},
+	}
+	for _, test := range tests {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := pos.Search(context.Background(), Limits{Depth: 4}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mate != test.mate {
+			t.Errorf("Search of %q should find mate %d but finds %d with %v", test.fen, test.mate, info.Mate, info.PV)
+		}
+		if test.best != "" && info.PV[0].UCI() != test.best {
+			t.Errorf("Search of %q should play %s but plays %v", test.fen, test.best, info.PV[0])
+		}
+	}
+}
+
+func TestSearchWinsMaterial(t *testing.T) {
+	pos, err := ParseFEN("4k3/8/8/3q4/8/8/3R4/3RK3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := pos.Search(context.Background(), Limits{Depth: 3}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.PV[0].UCI() != "d2d5" || info.Score < 500 {
+		t.Errorf("Search should capture the queen with d2d5 but plays %v with score %d", info.PV[0], info.Score)
+	}
+}
+
+func TestSearchDepthAndReports(t *testing.T) {
+	pos := NewPosition()
+	before := pos.FEN()
+	var depths []int
+	info, err := pos.Search(context.Background(), Limits{Depth: 4}, func(info SearchInfo) {
+		depths = append(depths, info.Depth)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(depths, []int{1, 2, 3, 4}) || info.Depth != 4 {
+		t.Errorf("Search should report depths 1 to 4 but reports %v and ends at %d", depths, info.Depth)
+	}
+	if !slices.Contains(pos.LegalMoves(), info.PV[0]) || len(info.PV) < 4 {
+		t.Errorf("Search should find a legal move with a full principal variation but finds %v", info.PV)
+	}
+	if pos.FEN() != before {
+		t.Errorf("Search should leave %q unchanged but gives %q", before, pos.FEN())
+	}
+}
+
+func TestSearchStops(t *testing.T) {
+	pos := NewPosition()
+	start := time.Now()
+	info, err := pos.Search(context.Background(), Limits{Time: 100 * time.Millisecond}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second || !slices.Contains(pos.LegalMoves(), info.PV[0]) {
+		t.Errorf("Search with 100ms should return a legal move in time but takes %v for %v", elapsed, info.PV)
+	}
+	info, err = pos.Search(context.Background(), Limits{Nodes: 5000}, nil)
+	if err != nil || info.Nodes > 5000 || !slices.Contains(pos.LegalMoves(), info.PV[0]) {
+		t.Errorf("Search with 5000 nodes should return a legal move within the limit but searches %d nodes for %v",
+			info.Nodes, info.PV)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	info, err = pos.Search(ctx, Limits{}, nil)
+	if err != nil || !slices.Contains(pos.LegalMoves(), info.PV[0]) {
+		t.Errorf("Cancelled search should still return a legal move but returns %v, %v", info.PV, err)
+	}
+}
+
+func TestSearchGameOver(t *testing.T) {
+	pos, err := ParseFEN("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pos.Search(context.Background(), Limits{Depth: 1}, nil); err == nil {
+		t.Error("Search in a stalemate should error but does not")
+	}
+}
+
+func TestEvaluateSymmetric(t *testing.T) {
+	for _, test := range perftPositions {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eval := pos.evaluate()
+		pos.turn = !pos.turn
+		if pos.evaluate() != -eval {
+			t.Errorf("Evaluation of %q should flip sign with the side to move", test.fen)
+		}
+	}
+	pos := NewPosition()
+	if eval := pos.evaluate(); eval != 0 {
+		t.Errorf("Starting position should evaluate to 0 but evaluates to %d", eval)
+	}
+}
@@ -0,0 +1,89 @@
+package chess
+
+import (
+	"unsafe"
+)
+
+type bound uint8
+
+const (
+	noBound bound = iota
+	exactBound
+	lowerBound
+	upperBound
+)
+
+type ttEntry struct {
+	key   uint64
+	move  Move
+	score int16
+	depth int8
+	bound bound
+	age   uint8
+}
+
+type TranspositionTable struct {
+	entries []ttEntry
+	age     uint8
+}
+
+func NewTranspositionTable(megabytes int) *TranspositionTable {
+	size := max(megabytes, 1) << 20 / int(unsafe.Sizeof(ttEntry{}))
+	return &TranspositionTable{entries: make([]ttEntry, size)}
+}
+
+func (table *TranspositionTable) Clear() {
+	clear(table.entries)
+	table.age = 0
+}
+
+func (table *TranspositionTable) HashFull() int {
+	sample := min(len(table.entries), 1000)
+	used := 0
+	for _, entry := range table.entries[:sample] {
+		if entry.bound != noBound && entry.age == table.age {
+			used++
+		}
+	}
+	return used * 1000 / sample
+}
+
+func (table *TranspositionTable) newSearch() {
+	table.age++
+}
+
+func (table *TranspositionTable) probe(key uint64) (ttEntry, bool) {
+	entry := table.entries[key%uint64(len(table.entries))]
+	return entry, entry.bound != noBound && entry.key == key
+}
+
+func (table *TranspositionTable) store(key uint64, depth int, b bound, score int, move Move) {
+	entry := &table.entries[key%uint64(len(table.entries))]
+	if entry.bound != noBound && entry.key != key && entry.age == table.age && int(entry.depth) > depth {
+		return
+	}
+	if move == 0 && entry.key == key {
+		move = entry.move
+	}
+	*entry = ttEntry{key: key, move: move, score: int16(score), depth: int8(depth), bound: b, age: table.age}
+}
+
+func scoreToTable(score, ply int) int {
+	switch {
+	case score > mateScore-maxPly:
+		return score + ply
+	case score < -mateScore+maxPly:
+		return score - ply
+	}
+	return score
+}
+
+func scoreFromTable(score, ply int) int {
+	switch {
+	case score > mateScore-maxPly:
+		return score - ply
+	case score < -mateScore+maxPly:
+		return score + ply
+	}
+	return score
+}
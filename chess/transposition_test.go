@@ -0,0 +1,96 @@
+package chess
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTranspositionTableStoreProbe(t *testing.T) {
+	table := NewTranspositionTable(1)
+	m := newMove(Square{_e, _2}, Square{_e, _4}, empty, doublePushFlag)
+	if _, found := table.probe(42); found {
+		t.Error("Empty table should not find a key but does")
+	}
+	table.store(42, 5, exactBound, -17, m)
+	entry, found := table.probe(42)
+	if !found || entry.depth != 5 || entry.bound != exactBound || entry.score != -17 || entry.move != m {
+		t.Errorf("Stored entry should be found unchanged but is %+v, %v", entry, found)
+	}
+	if _, found := table.probe(42 + uint64(len(table.entries))); found {
+		t.Error("Key sharing a slot should not be found but is")
+	}
+	table.Clear()
+	if _, found := table.probe(42); found || table.HashFull() != 0 {
+		t.Error("Cleared table should be empty but is not")
+	}
+}
+
+func TestTranspositionTableReplacement(t *testing.T) {
+	table := NewTranspositionTable(1)
+	other := 42 + uint64(len(table.entries))
+	table.store(42, 8, lowerBound, 100, 0)
+	table.store(other, 3, exactBound, 0, 0)
+	if _, found := table.probe(42); !found {
+		t.Error("Shallow entry should not replace a deeper one of the same search but does")
+	}
+	table.store(other, 9, exactBound, 0, 0)
+	if _, found := table.probe(other); !found {
+		t.Error("Deeper entry should replace a shallower one but does not")
+	}
+	table.newSearch()
+	table.store(42, 1, upperBound, 0, 0)
+	if _, found := table.probe(42); !found {
+		t.Error("Entry of a new search should replace an old one but does not")
+	}
+	m := newMove(Square{_g, _1}, Square{_f, _3}, empty, 0)
+	table.store(42, 2, lowerBound, 50, m)
+	table.store(42, 3, upperBound, 10, 0)
+	if entry, _ := table.probe(42); entry.move != m {
+		t.Errorf("Entry without a move should keep the known move %v but has %v", m, entry.move)
+	}
+}
+
+func TestTranspositionTableHashFull(t *testing.T) {
+	table := NewTranspositionTable(1)
+	for key := uint64(0); key < 500; key++ {
+		table.store(key, 1, exactBound, 0, 0)
+	}
+	if full := table.HashFull(); full != 500 {
+		t.Errorf("Table with half of the sampled slots used should report 500 but reports %d", full)
+	}
+	table.newSearch()
+	if full := table.HashFull(); full != 0 {
+		t.Errorf("Entries of an old search should not count as full but report %d", full)
+	}
+}
+
+func TestTableMateScores(t *testing.T) {
+	for _, score := range []int{mateScore - 3, -mateScore + 4, 250, -40} {
+		if got := scoreFromTable(scoreToTable(score, 7), 7); got != score {
+			t.Errorf("Score %d should survive the table but becomes %d", score, got)
+		}
+	}
+	if got := scoreFromTable(scoreToTable(mateScore-3, 7), 5); got != mateScore-1 {
+		t.Errorf("Mate found 3 plies from ply 7 should be 1 ply from ply 5 but is %d", mateScore-got)
+	}
+}
+
+func TestSearchWithTable(t *testing.T) {
+	table := NewTranspositionTable(4)
+	pos, err := ParseFEN("kbK5/pp6/1P6/8/8/8/8/R7 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		info, err := pos.Search(context.Background(), Limits{Depth: 5, Table: table}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mate != 2 || info.PV[0].UCI() != "a1a6" || len(info.PV) != 3 {
+			t.Errorf("Search with a table should find mate in 2 with a1a6 but finds %d with %v", info.Mate, info.PV)
+		}
+	}
+	if entry, found := table.probe(pos.Hash()); !found || entry.move.UCI() != "a1a6" {
+		t.Errorf("Table should hold the best move of the root but holds %+v, %v", entry, found)
+	}
+}
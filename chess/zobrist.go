@@ -0,0 +1,41 @@
+package chess
+
+var (
+	zobristPieces    [12][64]uint64
+	zobristCastling  [16]uint64
+	zobristEnPassant [8]uint64
+	zobristBlack     uint64
+)
+
+func init() {
+	state := uint64(0x9e3779b97f4a7c15)
+	next := func() uint64 {
+		state ^= state >> 12
+		state ^= state << 25
+		state ^= state >> 27
+		return state * 0x2545f4914f6cdd1d
+	}
+	for p := range zobristPieces {
+		for i := range zobristPieces[p] {
+			zobristPieces[p][i] = next()
+		}
+	}
+	for i := range zobristCastling {
+		zobristCastling[i] = next()
+	}
+	for i := range zobristEnPassant {
+		zobristEnPassant[i] = next()
+	}
+	zobristBlack = next()
+}
+
+func (position *Position) Hash() uint64 {
+	hash := position.board.hash ^ zobristCastling[position.castling]
+	if position.turn == Black {
+		hash ^= zobristBlack
+	}
+	if ep, ok := position.enPassantTarget(); ok {
+		hash ^= zobristEnPassant[ep.file]
+	}
+	return hash
+}
@@ -0,0 +1,103 @@
+package chess
+
+import (
+	"testing"
+)
+
+func (board *board) computeHash() (hash uint64) {
+	for i, p := range board.squares {
+		if p != empty {
+			hash ^= zobristPieces[p.index()][i]
+		}
+	}
+	return
+}
+
+func (position *Position) scratchHash() uint64 {
+	hash := position.board.computeHash() ^ zobristCastling[position.castling]
+	if position.turn == Black {
+		hash ^= zobristBlack
+	}
+	if ep, ok := position.enPassantTarget(); ok {
+		hash ^= zobristEnPassant[ep.file]
+	}
+	return hash
+}
+
+func TestHashIncremental(t *testing.T) {
+	for _, test := range perftPositions {
+		pos, err := ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := pos.Hash()
+		var list moveList
+		pos.generateMoves(&list)
+		for _, m := range list.slice() {
+			pos.makeMove(m)
+			if pos.Hash() != pos.scratchHash() {
+				t.Errorf("Hash after %v in %q should match the recomputed hash but does not", m, test.fen)
+			}
+			if pos.Hash() == before {
+				t.Errorf("Hash after %v in %q should differ from the hash before", m, test.fen)
+			}
+			pos.unmakeMove()
+			if pos.Hash() != before {
+				t.Errorf("Hash after unmaking %v in %q should be restored but is not", m, test.fen)
+			}
+		}
+	}
+}
+
+func TestHashTranspositions(t *testing.T) {
+	play := func(moves ...string) *Position {
+		pos := NewPosition()
+		for _, san := range moves {
+			m, err := pos.ParseMove(san)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := pos.Apply(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return pos
+	}
+	if play("e4", "e5", "Nf3").Hash() != play("Nf3", "e5", "e4").Hash() {
+		t.Error("Transposed move orders should give the same hash but do not")
+	}
+	if play("Nf3", "Nf6", "Ng1", "Ng8").Hash() != play().Hash() {
+		t.Error("Returning to the starting position should give the starting hash but does not")
+	}
+	if play("Nf3", "Nf6", "Rg1", "Rg8", "Rh1", "Rh8", "Ng1", "Ng8").Hash() == play().Hash() {
+		t.Error("Losing castling rights should change the hash but does not")
+	}
+	if play("e4").Hash() == play("e3", "a6", "e4").Hash() {
+		t.Error("Side to move should change the hash but does not")
+	}
+	withEnPassant, err := ParseFEN("rnbqkbnr/1pp1pppp/p7/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	without, err := ParseFEN("rnbqkbnr/1pp1pppp/p7/3pP3/8/8/PPPP1PPP/RNBQKBNR w KQkq - 0 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withEnPassant.Hash() != play("e4", "a6", "e5", "d5").Hash() {
+		t.Error("Position after a double push should hash like its FEN but does not")
+	}
+	if withEnPassant.Hash() == without.Hash() {
+		t.Error("Possible en passant capture should change the hash but does not")
+	}
+	pinned, err := ParseFEN("8/8/8/KPp4r/8/8/8/7k w - c6 0 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpinned, err := ParseFEN("8/8/8/KPp4r/8/8/8/7k w - - 0 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pinned.Hash() != unpinned.Hash() {
+		t.Error("Illegal en passant capture should not change the hash but does")
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"terminalchess/chess"
+)
+
+type openingBook struct {
+	book   *chess.Book
+	policy chess.BookPolicy
+}
+
+func loadBook(path string, policy chess.BookPolicy) (*openingBook, error) {
+	book, err := chess.OpenBook(path)
+	if err != nil {
+		return nil, err
+	}
+	return &openingBook{book: book, policy: policy}, nil
+}
+
+func (book *openingBook) pick(position *chess.Position) (chess.Move, bool) {
+	if book == nil {
+		return 0, false
+	}
+	return book.book.Pick(position, book.policy)
+}
+
+func bookCommand(out io.Writer, book *openingBook, position *chess.Position, args string) (*openingBook, error) {
+	command, path, _ := strings.Cut(strings.TrimSpace(args), " ")
+	switch command {
+	case "":
+		printBook(out, book, position)
+		return book, nil
+	case "load":
+		policy := chess.WeightedRandom
+		if book != nil {
+			policy = book.policy
+		}
+		loaded, err := loadBook(strings.TrimSpace(path), policy)
+		if err != nil {
+			return book, err
+		}
+		printBook(out, loaded, position)
+		return loaded, nil
+	case "off":
+		return nil, nil
+	case "best", "random":
+		if book == nil {
+			return nil, fmt.Errorf("No book is loaded, load one with \"book load <file>\".")
+		}
+		book.policy = chess.WeightedRandom
+		if command == "best" {
+			book.policy = chess.HighestWeight
+		}
+		return book, nil
+	}
+	return book, fmt.Errorf("Usage: book [load <file>|best|random|off].")
+}
+
+func printBook(out io.Writer, book *openingBook, position *chess.Position) {
+	if book == nil {
+		fmt.Fprintln(out, "No book is loaded, load one with \"book load <file>\".")
+		return
+	}
+	moves := book.book.Moves(position)
+	if len(moves) == 0 {
+		fmt.Fprintln(out, "The book has no moves for this position.")
+		return
+	}
+	total := 0
+	for _, m := range moves {
+		total += m.Weight
+	}
+	for _, m := range moves {
+		share := 0.0
+		if total > 0 {
+			share = float64(m.Weight) * 100 / float64(total)
+		}
+		fmt.Fprintf(out, "%-6v weight %5d (%.1f%%)\n", m.Move, m.Weight, share)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"terminalchess/chess"
+)
+
+func writeBook(t *testing.T, moves ...string) *openingBook {
+	var data []byte
+	position := chess.NewPosition()
+	for _, input := range moves {
+		m, err := position.ParseMove(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		from, to := m.From(), m.To()
+		raw := uint16((from.Rank()*8+from.File())<<6 | (to.Rank()*8 + to.File()))
+		data = binary.BigEndian.AppendUint64(data, position.PolyglotKey())
+		data = binary.BigEndian.AppendUint16(data, raw)
+		data = binary.BigEndian.AppendUint16(data, 1)
+		data = binary.BigEndian.AppendUint32(data, 0)
+		if err := position.Apply(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "book.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	book, err := loadBook(path, chess.HighestWeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return book
+}
+
+func TestUCIBook(t *testing.T) {
+	book := writeBook(t, "e2e4", "c7c5")
+	session := startSession(t, func(lines <-chan string, out io.Writer) {
+		engine := newUCIEngine(out)
+		engine.book = book
+		engine.run(lines)
+	})
+	session.send("position startpos moves e2e4")
+	session.send("go wtime 60000 btime 60000")
+	seen := session.expect("bestmove ")
+	if last := seen[len(seen)-1]; last != "bestmove c7c5" {
+		t.Errorf("Engine should play c7c5 from the book but outputs %q", seen)
+	}
+	session.send("position startpos moves d2d4")
+	session.send("go depth 2")
+	if seen := session.expect("bestmove "); len(seen) < 3 {
+		t.Errorf("Engine should search outside the book but outputs %q", seen)
+	}
+	session.quit()
+}
+
+func TestXBoardBook(t *testing.T) {
+	book := writeBook(t, "d2d4", "a7a6", "c2c4")
+	s := startSession(t, func(lines <-chan string, out io.Writer) {
+		engine := newXBoardEngine(out)
+		engine.book = book
+		engine.run(lines)
+	})
+	s.send("new")
+	s.send("usermove d2d4")
+	if seen := s.expect("move "); seen[len(seen)-1] != "move a7a6" {
+		t.Errorf("Engine should answer d2d4 with a7a6 from the book but outputs %q", seen)
+	}
+	s.quit()
+}
+
+func TestLoadBookMissing(t *testing.T) {
+	if _, err := loadBook(filepath.Join(t.TempDir(), "missing.bin"), chess.WeightedRandom); err == nil {
+		t.Error("Loading a missing book should fail but does not")
+	}
+}
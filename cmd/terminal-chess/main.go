@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"terminalchess/chess"
+)
+
+func main() {
+	uci := flag.Bool("uci", false, "speak the UCI protocol instead of running the interactive board")
+	xboard := flag.Bool("xboard", false, "speak the XBoard protocol instead of running the interactive board")
+	bookPath := flag.String("book", "", "play opening moves from this Polyglot book")
+	bookBest := flag.Bool("book-best", false, "always play the book move with the highest weight")
+	flag.Parse()
+	var book *openingBook
+	if *bookPath != "" {
+		policy := chess.WeightedRandom
+		if *bookBest {
+			policy = chess.HighestWeight
+		}
+		var err error
+		if book, err = loadBook(*bookPath, policy); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	lines := readLines(os.Stdin)
+	switch {
+	case *uci:
+		engine := newUCIEngine(os.Stdout)
+		engine.book = book
+		engine.run(lines)
+		return
+	case *xboard:
+		engine := newXBoardEngine(os.Stdout)
+		engine.book = book
+		engine.run(lines)
+		return
+	}
+	runREPL(lines, os.Stdout, book)
+}
+
+func runREPL(lines <-chan string, out io.Writer, book *openingBook) {
+	position := chess.NewPosition()
+	pieces := chess.UnicodePieces
+	var opponent *computer
+	printPosition(out, position, pieces)
+	for input := range lines {
+		command, args, _ := strings.Cut(input, " ")
+		switch command {
+		case "uci":
+			engine := newUCIEngine(out)
+			engine.book = book
+			engine.handle(input)
+			engine.run(lines)
+			return
+		case "xboard":
+			engine := newXBoardEngine(out)
+			engine.book = book
+			engine.run(lines)
+			return
+		case "draw":
+			if err := position.ClaimDraw(); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			fmt.Fprintf(out, "Game over: %v by %v.\n", position.Status(), position.Reason())
+		case "undo":
+			if err := stepToHuman(position, opponent, position.Undo); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			printPosition(out, position, pieces)
+		case "redo":
+			if err := stepToHuman(position, opponent, position.Redo); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			printPosition(out, position, pieces)
+		case "pieces":
+			set, err := chess.ParsePieceSet(args)
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			pieces = set
+			printPosition(out, position, pieces)
+		case "computer":
+			c, err := parseComputer(args)
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			opponent = c
+			playComputer(out, position, opponent, book, pieces)
+		case "book":
+			b, err := bookCommand(out, book, position, args)
+			if err != nil {
+				fmt.Fprintln(out, err)
+			}
+			book = b
+		case "perft", "divide":
+			printPerft(out, position, command, args)
+		case "fen":
+			fmt.Fprintln(out, position.FEN())
+		case "setfen":
+			if err := position.SetFEN(args); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			printPosition(out, position, pieces)
+			playComputer(out, position, opponent, book, pieces)
+		case "pgn":
+			tags := position.Tags()
+			if _, ok := tags["Date"]; !ok {
+				tags["Date"] = time.Now().Format("2006.01.02")
+			}
+			pgn := position.PGN(tags)
+			if args == "" {
+				fmt.Fprint(out, pgn)
+				continue
+			}
+			if err := os.WriteFile(args, []byte(pgn), 0644); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			fmt.Fprintf(out, "Game saved to %s.\n", args)
+		case "loadpgn":
+			if err := loadPGNFile(position, args); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			printPosition(out, position, pieces)
+			playComputer(out, position, opponent, book, pieces)
+		default:
+			m, err := position.ParseMove(input)
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			if err := position.Apply(m); err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			printPosition(out, position, pieces)
+			playComputer(out, position, opponent, book, pieces)
+		}
+	}
+}
+
+func printPosition(out io.Writer, position *chess.Position, pieces chess.PieceSet) {
+	if moves := position.FormatMoveList(); moves != "" {
+		fmt.Fprintln(out, moves)
+	}
+	fmt.Fprintln(out, position.Format(pieces))
+	if captured := position.Captured(); len(captured) > 0 {
+		fmt.Fprintf(out, "Captured: %s\n", pieces.Format(captured))
+	}
+	if position.Status() != chess.Ongoing {
+		fmt.Fprintf(out, "Game over: %v by %v.\n", position.Status(), position.Reason())
+		return
+	}
+	if position.InCheck() {
+		fmt.Fprintln(out, "Check!")
+	}
+	if reason := position.DrawClaim(); reason != chess.NoReason {
+		fmt.Fprintf(out, "A draw by %v can be claimed with \"draw\".\n", reason)
+	}
+}
+
+type computer struct {
+	color  chess.Color
+	limits chess.Limits
+}
+
+func parseComputer(args string) (*computer, error) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("Usage: computer white|black|off [depth|duration].")
+	}
+	c := &computer{limits: chess.Limits{Time: 2 * time.Second}}
+	switch fields[0] {
+	case "off":
+		return nil, nil
+	case "white":
+		c.color = chess.White
+	case "black":
+		c.color = chess.Black
+	default:
+		return nil, fmt.Errorf("Side %q is invalid, want white, black or off.", fields[0])
+	}
+	if len(fields) == 2 {
+		limits, err := parseLimits(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		c.limits = limits
+	}
+	c.limits.Table = chess.NewTranspositionTable(16)
+	return c, nil
+}
+
+func parseLimits(s string) (chess.Limits, error) {
+	if depth, err := strconv.Atoi(s); err == nil && depth > 0 {
+		return chess.Limits{Depth: depth}, nil
+	}
+	if duration, err := time.ParseDuration(s); err == nil && duration > 0 {
+		return chess.Limits{Time: duration}, nil
+	}
+	return chess.Limits{}, fmt.Errorf("Limit %q is neither a positive depth nor a duration like 5s.", s)
+}
+
+func playComputer(out io.Writer, position *chess.Position, opponent *computer, book *openingBook, pieces chess.PieceSet) {
+	if opponent == nil || position.Status() != chess.Ongoing || position.Turn() != opponent.color {
+		return
+	}
+	m, ok := book.pick(position)
+	details := "from the book"
+	if !ok {
+		info, err := position.Search(context.Background(), opponent.limits, nil)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		m = info.PV[0]
+		details = fmt.Sprintf("depth %d, score %s, %d nodes", info.Depth, formatScore(info), info.Nodes)
+	}
+	san, err := position.SAN(m)
+	if err == nil {
+		err = position.Apply(m)
+	}
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return
+	}
+	fmt.Fprintf(out, "Computer plays %s (%s).\n", san, details)
+	printPosition(out, position, pieces)
+}
+
+func stepToHuman(position *chess.Position, opponent *computer, step func() error) error {
+	if err := step(); err != nil {
+		return err
+	}
+	for opponent != nil && position.Turn() == opponent.color && step() == nil {
+	}
+	return nil
+}
+
+func formatScore(info chess.SearchInfo) string {
+	if info.Mate != 0 {
+		return fmt.Sprintf("mate in %d", info.Mate)
+	}
+	return fmt.Sprintf("%+.2f", float64(info.Score)/100)
+}
+
+func printPerft(out io.Writer, position *chess.Position, command, args string) {
+	depth, err := strconv.Atoi(args)
+	if err != nil || depth < 0 {
+		fmt.Fprintf(out, "Depth %q is not a non-negative number.\n", args)
+		return
+	}
+	start := time.Now()
+	nodes := 0
+	if command == "divide" {
+		divided := position.Divide(depth)
+		moves := make([]string, 0, len(divided))
+		for m := range divided {
+			moves = append(moves, m)
+		}
+		slices.Sort(moves)
+		for _, m := range moves {
+			fmt.Fprintf(out, "%s: %d\n", m, divided[m])
+			nodes += divided[m]
+		}
+	} else {
+		nodes = position.Perft(depth)
+	}
+	elapsed := time.Since(start)
+	fmt.Fprintf(out, "Nodes: %d (%v, %.0f nps)\n", nodes, elapsed.Round(time.Millisecond), float64(nodes)/elapsed.Seconds())
+}
+
+func loadPGNFile(position *chess.Position, args string) error {
+	name, number, hasNumber := strings.Cut(args, " ")
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	positions, err := chess.LoadPGN(file)
+	if err != nil {
+		return err
+	}
+	n := 1
+	if hasNumber {
+		n, err = strconv.Atoi(number)
+		if err != nil {
+			return fmt.Errorf("Game number %q is not a number.", number)
+		}
+	}
+	if n < 1 || n > len(positions) {
+		return fmt.Errorf("Game %d not found, %s contains %d games.", n, name, len(positions))
+	}
+	*position = *positions[n-1]
+	return nil
+}
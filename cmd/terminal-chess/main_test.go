@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"terminalchess/chess"
+)
+
+func startREPL(t *testing.T) *session {
+	return startSession(t, func(lines <-chan string, out io.Writer) {
+		runREPL(lines, out, nil)
+	})
+}
+
+func (session *session) close() {
+	session.in.Close()
+	select {
+	case <-session.closed:
+	case <-time.After(10 * time.Second):
+		session.t.Fatal("REPL should stop at the end of input but does not")
+	}
+}
+
+func TestREPLUndoRedoAgainstComputer(t *testing.T) {
+	start := chess.NewPosition().FEN()
+	session := startREPL(t)
+	session.send("computer black 1")
+	session.send("e4")
+	session.expect("Computer plays ")
+	seen := session.expect("1. e4 ")
+	moveList := seen[len(seen)-1]
+
+	session.send("undo")
+	session.send("fen")
+	seen = session.expect("r")
+	if fen := seen[len(seen)-1]; fen != start {
+		t.Errorf("undo should take back the computer's reply and e4 but reaches %q", fen)
+	}
+	if slices.ContainsFunc(seen, func(line string) bool { return strings.HasPrefix(line, "Computer plays ") }) {
+		t.Errorf("undo should not let the computer move again but shows %q", seen)
+	}
+
+	session.send("undo")
+	if seen := session.expect("No move"); seen[len(seen)-1] != "No move to undo." {
+		t.Errorf("undo in the start position should fail but shows %q", seen)
+	}
+
+	session.send("redo")
+	session.send("fen")
+	seen = session.expect("r")
+	if !slices.Contains(seen, moveList) {
+		t.Errorf("redo should restore %q but shows %q", moveList, seen)
+	}
+	for _, line := range seen {
+		if strings.HasPrefix(line, "Computer plays ") || strings.HasPrefix(line, "No move") {
+			t.Errorf("redo should replay the recorded moves but shows %q", line)
+		}
+	}
+	session.close()
+}
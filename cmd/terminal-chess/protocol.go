@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+)
+
+type lineWriter struct {
+	mutex sync.Mutex
+	out   io.Writer
+}
+
+func readLines(in io.Reader) <-chan string {
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+	return lines
+}
+
+func (writer *lineWriter) send(format string, args ...any) {
+	writer.mutex.Lock()
+	defer writer.mutex.Unlock()
+	fmt.Fprintf(writer.out, format+"\n", args...)
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"terminalchess/chess"
+)
+
+type uciEngine struct {
+	lineWriter
+	position *chess.Position
+	table    *chess.TranspositionTable
+	book     *openingBook
+	ponder   bool
+	overhead time.Duration
+
+	cancel   context.CancelFunc
+	done     chan struct{}
+	release  chan struct{}
+	released bool
+	pending  time.Duration
+}
+
+type uciGo struct {
+	limits    chess.Limits
+	wtime     time.Duration
+	btime     time.Duration
+	winc      time.Duration
+	binc      time.Duration
+	movesToGo int
+	infinite  bool
+	ponder    bool
+}
+
+func newUCIEngine(out io.Writer) *uciEngine {
+	return &uciEngine{lineWriter: lineWriter{out: out}, position: chess.NewPosition(),
+		table: chess.NewTranspositionTable(16), overhead: 50 * time.Millisecond}
+}
+
+func (engine *uciEngine) run(lines <-chan string) {
+	for line := range lines {
+		if !engine.handle(line) {
+			break
+		}
+	}
+	engine.stop()
+}
+
+func (engine *uciEngine) handle(line string) bool {
+	command, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	switch command {
+	case "uci":
+		engine.send("id name terminal-chess")
+		engine.send("id author the terminal-chess authors")
+		engine.send("option name Hash type spin default 16 min 1 max 1024")
+		engine.send("option name Ponder type check default false")
+		engine.send("option name Move Overhead type spin default 50 min 0 max 5000")
+		engine.send("uciok")
+	case "isready":
+		engine.send("readyok")
+	case "ucinewgame":
+		engine.stop()
+		engine.position = chess.NewPosition()
+		engine.table.Clear()
+	case "position":
+		engine.stop()
+		if err := engine.setPosition(args); err != nil {
+			engine.send("info string %v", err)
+		}
+	case "go":
+		engine.stop()
+		params, err := parseUCIGo(args)
+		if err != nil {
+			engine.send("info string %v", err)
+			return true
+		}
+		engine.start(params)
+	case "stop":
+		engine.stop()
+	case "ponderhit":
+		engine.ponderhit()
+	case "setoption":
+		engine.stop()
+		if err := engine.setOption(args); err != nil {
+			engine.send("info string %v", err)
+		}
+	case "quit":
+		return false
+	case "debug", "register", "":
+	default:
+		engine.send("info string Unknown command %q.", command)
+	}
+	return true
+}
+
+func (engine *uciEngine) setPosition(args string) error {
+	fields := strings.Fields(args)
+	var position *chess.Position
+	switch {
+	case len(fields) > 0 && fields[0] == "startpos":
+		position = chess.NewPosition()
+		fields = fields[1:]
+	case len(fields) > 0 && fields[0] == "fen":
+		end := len(fields)
+		for i, field := range fields {
+			if field == "moves" {
+				end = i
+				break
+			}
+		}
+		var err error
+		position, err = chess.ParseFEN(strings.Join(fields[1:end], " "))
+		if err != nil {
+			return err
+		}
+		fields = fields[end:]
+	default:
+		return fmt.Errorf("Position %q does not start with startpos or fen.", args)
+	}
+	if len(fields) > 0 && fields[0] == "moves" {
+		for _, input := range fields[1:] {
+			m, err := position.ParseMove(input)
+			if err == nil {
+				err = position.Apply(m)
+			}
+			if err != nil {
+				return fmt.Errorf("Move %s: %v", input, err)
+			}
+		}
+	}
+	engine.position = position
+	return nil
+}
+
+func parseUCIGo(args string) (uciGo, error) {
+	var params uciGo
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		name := fields[i]
+		switch name {
+		case "infinite":
+			params.infinite = true
+			continue
+		case "ponder":
+			params.ponder = true
+			continue
+		case "searchmoves":
+			return params, fmt.Errorf("Option searchmoves is not supported.")
+		}
+		if i+1 >= len(fields) {
+			return params, fmt.Errorf("Option %s needs a value.", name)
+		}
+		i++
+		value, err := strconv.Atoi(fields[i])
+		if err != nil {
+			return params, fmt.Errorf("Value %q of option %s is not a number.", fields[i], name)
+		}
+		millis := time.Duration(value) * time.Millisecond
+		switch name {
+		case "depth":
+			params.limits.Depth = value
+		case "movetime":
+			params.limits.Time = millis
+		case "wtime":
+			params.wtime = millis
+		case "btime":
+			params.btime = millis
+		case "winc":
+			params.winc = millis
+		case "binc":
+			params.binc = millis
+		case "movestogo":
+			params.movesToGo = value
+		case "nodes":
+			params.limits.Nodes = value
+		case "mate":
+			if value <= 0 {
+				return params, fmt.Errorf("Value %d of option mate is not a positive number of moves.", value)
+			}
+			params.limits.Depth = 2*value - 1
+		default:
+			return params, fmt.Errorf("Option %s is unknown.", name)
+		}
+	}
+	return params, nil
+}
+
+func (engine *uciEngine) budget(params uciGo) time.Duration {
+	if params.limits.Time > 0 {
+		return params.limits.Time
+	}
+	remaining, increment := params.wtime, params.winc
+	if engine.position.Turn() == chess.Black {
+		remaining, increment = params.btime, params.binc
+	}
+	if remaining <= 0 {
+		return 0
+	}
+	movesToGo := params.movesToGo
+	if movesToGo <= 0 {
+		movesToGo = 30
+	}
+	budget := remaining/time.Duration(movesToGo) + increment*3/4
+	return max(min(budget, remaining-engine.overhead), time.Millisecond)
+}
+
+func (engine *uciEngine) start(params uciGo) {
+	if !params.infinite && !params.ponder {
+		if m, ok := engine.book.pick(engine.position); ok {
+			engine.send("info string book move %v", m)
+			engine.send("bestmove %v", m)
+			return
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	limits := chess.Limits{Depth: params.limits.Depth, Nodes: params.limits.Nodes, Table: engine.table}
+	budget := engine.budget(params)
+	wait := params.infinite || params.ponder || (limits.Depth == 0 && limits.Nodes == 0 && budget == 0)
+	if !params.ponder {
+		limits.Time = budget
+	}
+	engine.cancel, engine.done = cancel, make(chan struct{})
+	engine.release, engine.released = make(chan struct{}), !wait
+	engine.pending = 0
+	if params.ponder {
+		engine.pending = budget
+	}
+	position, done, release, ponder := engine.position.Clone(), engine.done, engine.release, engine.ponder
+	go func() {
+		defer close(done)
+		info, err := position.Search(ctx, limits, engine.report)
+		if wait {
+			<-release
+		}
+		if err != nil || len(info.PV) == 0 {
+			engine.send("bestmove 0000")
+			return
+		}
+		if ponder && len(info.PV) > 1 {
+			engine.send("bestmove %v ponder %v", info.PV[0], info.PV[1])
+			return
+		}
+		engine.send("bestmove %v", info.PV[0])
+	}()
+}
+
+func (engine *uciEngine) ponderhit() {
+	if engine.done == nil || engine.released {
+		return
+	}
+	engine.released = true
+	close(engine.release)
+	if engine.pending > 0 {
+		time.AfterFunc(engine.pending, engine.cancel)
+	}
+}
+
+func (engine *uciEngine) stop() {
+	if engine.done == nil {
+		return
+	}
+	engine.cancel()
+	if !engine.released {
+		engine.released = true
+		close(engine.release)
+	}
+	<-engine.done
+	engine.done = nil
+}
+
+func (engine *uciEngine) report(info chess.SearchInfo) {
+	score := fmt.Sprintf("cp %d", info.Score)
+	if info.Mate != 0 {
+		score = fmt.Sprintf("mate %d", info.Mate)
+	}
+	nps := int64(float64(info.Nodes) / max(info.Time.Seconds(), 0.001))
+	pv := make([]string, len(info.PV))
+	for i, m := range info.PV {
+		pv[i] = m.UCI()
+	}
+	engine.send("info depth %d score %s nodes %d nps %d hashfull %d time %d pv %s", info.Depth, score, info.Nodes,
+		nps, info.HashFull, info.Time.Milliseconds(), strings.Join(pv, " "))
+}
+
+func (engine *uciEngine) setOption(args string) error {
+	rest, ok := strings.CutPrefix(args, "name ")
+	if !ok {
+		return fmt.Errorf("Option %q has no name.", args)
+	}
+	name, value, _ := strings.Cut(rest, " value ")
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "hash":
+		megabytes, err := strconv.Atoi(value)
+		if err != nil || megabytes < 1 || megabytes > 1024 {
+			return fmt.Errorf("Hash %q is not a number between 1 and 1024.", value)
+		}
+		engine.table = chess.NewTranspositionTable(megabytes)
+	case "ponder":
+		engine.ponder = value == "true"
+	case "move overhead":
+		millis, err := strconv.Atoi(value)
+		if err != nil || millis < 0 {
+			return fmt.Errorf("Move Overhead %q is not a non-negative number.", value)
+		}
+		engine.overhead = time.Duration(millis) * time.Millisecond
+	default:
+		return fmt.Errorf("Option %q is unknown.", name)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type session struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	lines  chan string
+	closed chan struct{}
+}
+
+func startUCI(t *testing.T) *session {
+	return startSession(t, func(lines <-chan string, out io.Writer) {
+		newUCIEngine(out).run(lines)
+	})
+}
+
+func startSession(t *testing.T, run func(lines <-chan string, out io.Writer)) *session {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	session := &session{t: t, in: inWriter, lines: make(chan string, 1000), closed: make(chan struct{})}
+	go func() {
+		run(readLines(inReader), outWriter)
+		outWriter.Close()
+		close(session.closed)
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		for scanner.Scan() {
+			session.lines <- scanner.Text()
+		}
+		close(session.lines)
+	}()
+	return session
+}
+
+func (session *session) send(line string) {
+	if _, err := io.WriteString(session.in, line+"\n"); err != nil {
+		session.t.Fatal(err)
+	}
+}
+
+func (session *session) expect(prefix string) (seen []string) {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case line, ok := <-session.lines:
+			if !ok {
+				session.t.Fatalf("Output should contain %q but ends after %q", prefix, seen)
+			}
+			seen = append(seen, line)
+			if strings.HasPrefix(line, prefix) {
+				return seen
+			}
+		case <-timeout:
+			session.t.Fatalf("Output should contain %q but only has %q", prefix, seen)
+		}
+	}
+}
+
+func (session *session) quit() {
+	session.send("quit")
+	select {
+	case <-session.closed:
+	case <-time.After(10 * time.Second):
+		session.t.Fatal("Engine should quit but does not")
+	}
+}
+
+func TestUCIHandshake(t *testing.T) {
+	session := startUCI(t)
+	session.send("uci")
+	seen := session.expect("uciok")
+	if !strings.HasPrefix(seen[0], "id name ") {
+		t.Errorf("UCI handshake should start with the engine name but starts with %q", seen[0])
+	}
+	session.send("isready")
+	session.expect("readyok")
+	session.quit()
+}
+
+func TestUCIGoDepth(t *testing.T) {
+	session := startUCI(t)
+	session.send("ucinewgame")
+	session.send("position startpos moves e2e4 e7e5 g1f3")
+	session.send("go depth 3")
+	seen := session.expect("bestmove ")
+	if len(seen) < 4 || !strings.HasPrefix(seen[2], "info depth 3 score cp ") || !strings.Contains(seen[2], " pv ") {
+		t.Errorf("Search to depth 3 should report three info lines but reports %q", seen)
+	}
+	session.quit()
+}
+
+func TestUCIMate(t *testing.T) {
+	session := startUCI(t)
+	session.send("position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	session.send("go movetime 1000")
+	seen := session.expect("bestmove ")
+	if last := seen[len(seen)-1]; last != "bestmove a1a8" || !strings.Contains(seen[len(seen)-2], "score mate 1") {
+		t.Errorf("Engine should announce and play mate in 1 but outputs %q", seen)
+	}
+	session.quit()
+}
+
+func TestUCIGoNodesAndMate(t *testing.T) {
+	session := startUCI(t)
+	session.send("position startpos")
+	session.send("go nodes 20000")
+	seen := session.expect("bestmove ")
+	if len(seen) < 2 || len(seen) > 8 {
+		t.Errorf("Search limited to 20000 nodes should stop after a few depths but outputs %q", seen)
+	}
+	session.send("position fen kbK5/pp6/1P6/8/8/8/8/R7 w - - 0 1")
+	session.send("go mate 2")
+	seen = session.expect("bestmove ")
+	if last := seen[len(seen)-1]; last != "bestmove a1a6" || !strings.Contains(seen[len(seen)-2], "score mate 2") {
+		t.Errorf("Engine should find the mate in 2 but outputs %q", seen)
+	}
+	session.send("go mate 0")
+	session.expect("info string Value 0 of option mate")
+	session.quit()
+}
+
+func TestUCIPonderOption(t *testing.T) {
+	session := startUCI(t)
+	session.send("position startpos")
+	session.send("go depth 3")
+	if last := session.expect("bestmove "); strings.Contains(last[len(last)-1], " ponder ") {
+		t.Errorf("Engine should not suggest a ponder move with Ponder off but outputs %q", last)
+	}
+	session.send("setoption name Ponder value true")
+	session.send("go depth 3")
+	if last := session.expect("bestmove "); !strings.Contains(last[len(last)-1], " ponder ") {
+		t.Errorf("Engine should suggest a ponder move with Ponder on but outputs %q", last)
+	}
+	session.quit()
+}
+
+func TestUCIInfiniteStop(t *testing.T) {
+	session := startUCI(t)
+	session.send("position startpos")
+	session.send("go infinite")
+	session.expect("info depth 2 ")
+	session.send("isready")
+	session.expect("readyok")
+	session.send("stop")
+	session.expect("bestmove ")
+	session.quit()
+}
+
+func TestUCIPonderhit(t *testing.T) {
+	session := startUCI(t)
+	session.send("position startpos moves e2e4 e7e5")
+	session.send("go ponder wtime 3000 btime 3000")
+	session.expect("info depth 2 ")
+	session.send("ponderhit")
+	session.expect("bestmove ")
+	session.quit()
+}
+
+func TestUCIClock(t *testing.T) {
+	session := startUCI(t)
+	session.send("setoption name Move Overhead value 10")
+	session.send("position startpos")
+	start := time.Now()
+	session.send("go wtime 2000 btime 2000 winc 0 binc 0")
+	session.expect("bestmove ")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Move with 2s on the clock should take a fraction of it but takes %v", elapsed)
+	}
+	session.quit()
+}
+
+func TestUCIHash(t *testing.T) {
+	session := startUCI(t)
+	session.send("setoption name Hash value 1")
+	session.send("position startpos")
+	session.send("go depth 5")
+	seen := session.expect("bestmove ")
+	if info := seen[len(seen)-2]; !strings.Contains(info, " hashfull ") || strings.Contains(info, " hashfull 0 ") {
+		t.Errorf("Search should report a used hash table but reports %q", info)
+	}
+	session.send("setoption name Hash value 0")
+	session.expect("info string Hash \"0\" is not a number between 1 and 1024.")
+	session.quit()
+}
+
+func TestUCIErrors(t *testing.T) {
+	session := startUCI(t)
+	session.send("position startpos moves e2e5")
+	session.expect("info string Move e2e5: ")
+	session.send("setoption name Colour value blue")
+	session.expect("info string Option")
+	session.send("go depth")
+	session.expect("info string Option depth needs a value.")
+	session.send("position fen 7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	session.send("go depth 1")
+	session.expect("bestmove 0000")
+	session.quit()
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"terminalchess/chess"
+)
+
+type xboardEngine struct {
+	lineWriter
+	position *chess.Position
+	table    *chess.TranspositionTable
+	book     *openingBook
+	force    bool
+	color    chess.Color
+	post     bool
+
+	depth     int
+	moveTime  time.Duration
+	movesPer  int
+	increment time.Duration
+	clock     time.Duration
+	overhead  time.Duration
+
+	cancel context.CancelFunc
+	result chan xboardResult
+}
+
+type xboardResult struct {
+	info chess.SearchInfo
+	err  error
+}
+
+func newXBoardEngine(out io.Writer) *xboardEngine {
+	engine := &xboardEngine{lineWriter: lineWriter{out: out}, table: chess.NewTranspositionTable(16), movesPer: 40,
+		clock: 5 * time.Minute, overhead: 50 * time.Millisecond}
+	engine.reset()
+	return engine
+}
+
+func (engine *xboardEngine) reset() {
+	engine.position = chess.NewPosition()
+	engine.table.Clear()
+	engine.force = false
+	engine.color = chess.Black
+	engine.depth = 0
+}
+
+func (engine *xboardEngine) run(lines <-chan string) {
+	defer engine.stop()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok || !engine.handle(line) {
+				return
+			}
+		case result := <-engine.result:
+			engine.result = nil
+			engine.play(result)
+		}
+	}
+}
+
+func (engine *xboardEngine) handle(line string) bool {
+	command, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	switch command {
+	case "protover":
+		engine.send(`feature myname="terminal-chess" ping=1 setboard=1 usermove=1 playother=1 san=0 time=1 ` +
+			`memory=1 colors=0 sigint=0 sigterm=0 reuse=1 analyze=0 done=1`)
+	case "new":
+		engine.stop()
+		engine.reset()
+	case "variant":
+		if args != "normal" {
+			engine.send("Error (unsupported variant): %s", args)
+		}
+	case "force":
+		engine.stop()
+		engine.force = true
+	case "go":
+		engine.stop()
+		engine.force = false
+		engine.color = engine.position.Turn()
+		engine.think()
+	case "playother":
+		engine.stop()
+		engine.force = false
+		engine.color = !engine.position.Turn()
+	case "usermove":
+		engine.usermove(args)
+	case "?":
+		if engine.cancel != nil {
+			engine.cancel()
+		}
+	case "undo", "remove":
+		engine.stop()
+		plies := 1
+		if command == "remove" {
+			plies = 2
+		}
+		for range plies {
+			if err := engine.position.Undo(); err != nil {
+				engine.send("Error (%v): %s", err, command)
+				break
+			}
+		}
+	case "setboard":
+		engine.stop()
+		position, err := chess.ParseFEN(args)
+		if err != nil {
+			engine.send("tellusererror Illegal position: %v", err)
+			return true
+		}
+		engine.position = position
+	case "level":
+		if err := engine.setLevel(args); err != nil {
+			engine.send("Error (%v): %s", err, line)
+		}
+	case "st":
+		seconds, err := strconv.ParseFloat(args, 64)
+		if err != nil || seconds <= 0 {
+			engine.send("Error (time per move is not a positive number): %s", line)
+			return true
+		}
+		engine.moveTime = time.Duration(seconds * float64(time.Second))
+	case "sd":
+		depth, err := strconv.Atoi(args)
+		if err != nil || depth <= 0 {
+			engine.send("Error (depth is not a positive number): %s", line)
+			return true
+		}
+		engine.depth = depth
+	case "time":
+		centis, err := strconv.Atoi(args)
+		if err != nil {
+			engine.send("Error (time is not a number): %s", line)
+			return true
+		}
+		engine.clock = time.Duration(centis) * 10 * time.Millisecond
+	case "memory":
+		megabytes, err := strconv.Atoi(args)
+		if err != nil || megabytes < 1 {
+			engine.send("Error (memory is not a positive number): %s", line)
+			return true
+		}
+		engine.stop()
+		engine.table = chess.NewTranspositionTable(megabytes)
+	case "post":
+		engine.post = true
+	case "nopost":
+		engine.post = false
+	case "ping":
+		engine.send("pong %s", args)
+	case "result":
+		engine.stop()
+		engine.force = true
+	case "quit":
+		return false
+	case "xboard", "accepted", "rejected", "otim", "random", "hard", "easy", "computer", "name", "rating", "ics",
+		"draw", "hint", "bk", "":
+	default:
+		if engine.position.Status() == chess.Ongoing {
+			if _, err := engine.position.ParseMove(line); err == nil {
+				engine.usermove(line)
+				return true
+			}
+		}
+		engine.send("Error (unknown command): %s", command)
+	}
+	return true
+}
+
+func (engine *xboardEngine) usermove(input string) {
+	engine.stop()
+	m, err := engine.position.ParseMove(input)
+	if err == nil {
+		err = engine.position.Apply(m)
+	}
+	if err != nil {
+		engine.send("Illegal move (%v): %s", err, input)
+		return
+	}
+	if engine.sendResult() || engine.force || engine.position.Turn() != engine.color {
+		return
+	}
+	engine.think()
+}
+
+func (engine *xboardEngine) setLevel(args string) error {
+	fields := strings.Fields(args)
+	if len(fields) != 3 {
+		return fmt.Errorf("level needs moves, base and increment")
+	}
+	movesPer, err := strconv.Atoi(fields[0])
+	if err != nil || movesPer < 0 {
+		return fmt.Errorf("moves per session is not a number")
+	}
+	minutes, seconds, _ := strings.Cut(fields[1], ":")
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return fmt.Errorf("base time is not a number")
+	}
+	base := time.Duration(m) * time.Minute
+	if seconds != "" {
+		s, err := strconv.Atoi(seconds)
+		if err != nil {
+			return fmt.Errorf("base time seconds are not a number")
+		}
+		base += time.Duration(s) * time.Second
+	}
+	increment, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return fmt.Errorf("increment is not a number")
+	}
+	engine.movesPer = movesPer
+	engine.increment = time.Duration(increment * float64(time.Second))
+	engine.clock, engine.moveTime = base, 0
+	return nil
+}
+
+func (engine *xboardEngine) limits() chess.Limits {
+	limits := chess.Limits{Depth: engine.depth, Time: engine.moveTime, Table: engine.table}
+	if limits.Time > 0 || engine.clock <= 0 {
+		return limits
+	}
+	movesToGo := 30
+	if engine.movesPer > 0 {
+		played := (engine.position.FullmoveNumber() - 1) % engine.movesPer
+		movesToGo = engine.movesPer - played
+	}
+	budget := engine.clock/time.Duration(movesToGo) + engine.increment*3/4
+	limits.Time = max(min(budget, engine.clock-engine.overhead), 10*time.Millisecond)
+	return limits
+}
+
+func (engine *xboardEngine) think() {
+	if engine.position.Status() != chess.Ongoing {
+		return
+	}
+	if m, ok := engine.book.pick(engine.position); ok {
+		engine.play(xboardResult{info: chess.SearchInfo{PV: []chess.Move{m}}})
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan xboardResult, 1)
+	engine.cancel, engine.result = cancel, result
+	position, limits := engine.position.Clone(), engine.limits()
+	var report func(chess.SearchInfo)
+	if engine.post {
+		report = engine.report
+	}
+	go func() {
+		info, err := position.Search(ctx, limits, report)
+		result <- xboardResult{info, err}
+	}()
+}
+
+func (engine *xboardEngine) stop() {
+	if engine.result == nil {
+		return
+	}
+	engine.cancel()
+	<-engine.result
+	engine.result = nil
+}
+
+func (engine *xboardEngine) play(result xboardResult) {
+	if result.err != nil || len(result.info.PV) == 0 {
+		engine.send("Error (%v): go", result.err)
+		return
+	}
+	m := result.info.PV[0]
+	if err := engine.position.Apply(m); err != nil {
+		engine.send("Error (%v): go", err)
+		return
+	}
+	engine.send("move %v", m)
+	engine.sendResult()
+}
+
+func (engine *xboardEngine) sendResult() bool {
+	status := engine.position.Status()
+	if status == chess.Ongoing {
+		return false
+	}
+	result := "1/2-1/2"
+	switch status {
+	case chess.WhiteWins:
+		result = "1-0"
+	case chess.BlackWins:
+		result = "0-1"
+	}
+	engine.send("%s {%v by %v}", result, status, engine.position.Reason())
+	return true
+}
+
+func (engine *xboardEngine) report(info chess.SearchInfo) {
+	score := info.Score
+	if info.Mate > 0 {
+		score = 100000 + info.Mate
+	} else if info.Mate < 0 {
+		score = -100000 + info.Mate
+	}
+	pv := make([]string, len(info.PV))
+	for i, m := range info.PV {
+		pv[i] = m.UCI()
+	}
+	engine.send("%d %d %d %d %s", info.Depth, score, info.Time.Milliseconds()/10, info.Nodes, strings.Join(pv, " "))
+}
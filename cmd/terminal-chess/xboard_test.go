@@ -0,0 +1,98 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func startXBoard(t *testing.T) *session {
+	s := startSession(t, func(lines <-chan string, out io.Writer) {
+		newXBoardEngine(out).run(lines)
+	})
+	s.send("xboard")
+	s.send("protover 2")
+	s.expect("feature ")
+	return s
+}
+
+func TestXBoardPlaysBlack(t *testing.T) {
+	s := startXBoard(t)
+	s.send("new")
+	s.send("sd 3")
+	s.send("post")
+	s.send("usermove e2e4")
+	seen := s.expect("move ")
+	if len(seen) < 4 || !strings.HasPrefix(seen[2], "3 ") {
+		t.Errorf("Engine should post three plies of thinking but outputs %q", seen)
+	}
+	s.send("ping 7")
+	s.expect("pong 7")
+	s.quit()
+}
+
+func TestXBoardForceAndGo(t *testing.T) {
+	s := startXBoard(t)
+	s.send("new")
+	s.send("force")
+	s.send("usermove e2e4")
+	s.send("usermove e7e5")
+	s.send("ping 1")
+	if seen := s.expect("pong 1"); len(seen) != 1 {
+		t.Errorf("Engine in force mode should not move but outputs %q", seen)
+	}
+	s.send("undo")
+	s.send("remove")
+	s.send("undo")
+	s.expect("Error (No move to undo.): undo")
+	s.send("st 0.2")
+	s.send("go")
+	s.expect("move ")
+	s.quit()
+}
+
+func TestXBoardSetboardMate(t *testing.T) {
+	s := startXBoard(t)
+	s.send("new")
+	s.send("force")
+	s.send("setboard 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	s.send("level 40 0:30 0")
+	s.send("time 3000")
+	s.send("otim 3000")
+	s.send("go")
+	seen := s.expect("1-0 ")
+	if seen[len(seen)-2] != "move a1a8" || seen[len(seen)-1] != "1-0 {white wins by checkmate}" {
+		t.Errorf("Engine should mate with a1a8 and claim the result but outputs %q", seen)
+	}
+	s.quit()
+}
+
+func TestXBoardErrors(t *testing.T) {
+	s := startXBoard(t)
+	s.send("new")
+	s.send("usermove e2e5")
+	s.expect("Illegal move (Pawn on e2 cannot move to e5.): e2e5")
+	s.send("setboard not a fen")
+	s.expect("tellusererror Illegal position: ")
+	s.send("level x 5 0")
+	s.expect("Error (moves per session is not a number): level x 5 0")
+	s.send("frobnicate")
+	s.expect("Error (unknown command): frobnicate")
+	s.quit()
+}
+
+func TestXBoardMoveNow(t *testing.T) {
+	s := startXBoard(t)
+	s.send("new")
+	s.send("st 0.2")
+	s.send("playother")
+	s.send("usermove d2d4")
+	s.expect("move ")
+	s.send("force")
+	s.send("setboard rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	s.send("go")
+	s.send("?")
+	s.expect("move ")
+	s.send("result 1-0 {White resigns}")
+	s.quit()
+}